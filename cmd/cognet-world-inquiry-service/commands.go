@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"cognet-world-inquiry-service/internal/config"
+	"cognet-world-inquiry-service/internal/model"
+	"cognet-world-inquiry-service/internal/service"
+	"cognet-world-inquiry-service/internal/store"
+)
+
+const usage = `Usage: cognet-world-inquiry-service [command]
+
+Commands:
+  serve                                  start the HTTP server (default)
+  import tsv [-mode m] [-dry-run] <file> import cognates from a TSV, CSV, JSONL, .gz or .zip file, - for stdin
+  import languages <file>                import language metadata from a JSON file
+  import concepts <file>                 import concept metadata from a JSON or JSON lines file
+  status [job-id]                        show the dataset versions, or an import job
+  clear [-scope s] -yes                  delete all, cognates, languages or concepts data
+  export [-format f] [-langs l] [-concept-prefix p] [-o file]
+                                         write the active dataset as tsv, jsonl or csv, to stdout by default
+
+Commands run against the store configured by STORAGE_BACKEND, progress is
+reported on stderr.
+`
+
+// errUsage reports a command line that does not name a valid command
+var errUsage = errors.New("invalid usage")
+
+// progressInterval throttles progress lines on stderr
+const progressInterval = time.Second
+
+// runCommand runs one of the offline subcommands against the configured
+// store
+func runCommand(command string, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch command {
+	case "import":
+		if len(args) == 0 {
+			return errUsage
+		}
+		switch args[0] {
+		case "tsv":
+			return importTSVCommand(ctx, args[1:])
+		case "languages":
+			return importLanguagesCommand(ctx, args[1:])
+		case "concepts":
+			return importConceptsCommand(ctx, args[1:])
+		}
+	case "status":
+		return statusCommand(ctx, args)
+	case "clear":
+		return clearCommand(ctx, args)
+	case "export":
+		return exportCommand(ctx, args)
+	}
+	return errUsage
+}
+
+// parseFlags parses the flags of a subcommand and checks its number of
+// positional arguments
+func parseFlags(flags *flag.FlagSet, args []string, positional int) error {
+	flags.SetOutput(io.Discard)
+	err := flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return errUsage
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", flags.Name(), err)
+	}
+	if flags.NArg() != positional {
+		return errUsage
+	}
+	return nil
+}
+
+// withStore opens the configured store for the duration of fn
+func withStore(fn func(cognateStore store.CognateStore) error) error {
+	cognateStore, err := newStore(config.AppConfig)
+	if err != nil {
+		return err
+	}
+	defer cognateStore.Close()
+
+	return fn(cognateStore)
+}
+
+// newImporter builds the importer used by the subcommands. Its language
+// registry is only reloaded when languages change, which also tells running
+// servers to reload theirs.
+func newImporter(cognateStore store.CognateStore) service.DataImporter {
+	return service.NewDataImporter(cognateStore, service.NewLanguageRegistry(cognateStore))
+}
+
+// openInput opens a file argument, - is stdin
+func openInput(name string) (*os.File, error) {
+	if name == "-" {
+		return os.Stdin, nil
+	}
+	return os.Open(name)
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func importTSVCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("import tsv", flag.ContinueOnError)
+	modeName := flags.String("mode", "append", "append, upsert or replace")
+	dryRun := flags.Bool("dry-run", false, "validate the file without writing")
+	if err := parseFlags(flags, args, 1); err != nil {
+		return err
+	}
+
+	mode, err := service.ParseImportMode(*modeName)
+	if err != nil {
+		return err
+	}
+
+	file, err := openInput(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return withStore(func(cognateStore store.CognateStore) error {
+		var lastProgress time.Time
+		opts := service.ImportOptions{
+			Mode:   mode,
+			DryRun: *dryRun,
+			Progress: func(job *model.ImportJob) {
+				if time.Since(lastProgress) >= progressInterval {
+					printImportProgress(job)
+					lastProgress = time.Now()
+				}
+			},
+		}
+
+		job, err := newImporter(cognateStore).ImportFromReader(ctx, file, opts)
+		if job != nil {
+			printImportProgress(job)
+			fmt.Fprintln(os.Stderr)
+			if printErr := printJSON(job); printErr != nil && err == nil {
+				err = printErr
+			}
+		}
+		return err
+	})
+}
+
+// printImportProgress rewrites the progress line of an import
+func printImportProgress(job *model.ImportJob) {
+	fmt.Fprintf(os.Stderr, "\r%d rows read, %d written, %d skipped, %d duplicate",
+		job.RowsRead, job.RowsWritten, job.RowsSkipped, job.RowsDuplicate)
+	if job.TotalBytes > 0 {
+		fmt.Fprintf(os.Stderr, " (%.1f%%)", 100*float64(job.BytesProcessed)/float64(job.TotalBytes))
+	}
+}
+
+func importLanguagesCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("import languages", flag.ContinueOnError)
+	if err := parseFlags(flags, args, 1); err != nil {
+		return err
+	}
+
+	file, err := openInput(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return withStore(func(cognateStore store.CognateStore) error {
+		if err := newImporter(cognateStore).ImportLanguages(ctx, bufio.NewReader(file)); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "Languages imported successfully")
+		return nil
+	})
+}
+
+func importConceptsCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("import concepts", flag.ContinueOnError)
+	if err := parseFlags(flags, args, 1); err != nil {
+		return err
+	}
+
+	file, err := openInput(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return withStore(func(cognateStore store.CognateStore) error {
+		imported, err := newImporter(cognateStore).ImportConcepts(ctx, file)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Imported %d concepts\n", imported)
+		return nil
+	})
+}
+
+func statusCommand(ctx context.Context, args []string) error {
+	if len(args) > 1 {
+		return errUsage
+	}
+
+	return withStore(func(cognateStore store.CognateStore) error {
+		if len(args) == 1 {
+			job, err := newImporter(cognateStore).GetImportJob(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			return printJSON(job)
+		}
+
+		versions, err := service.NewDatasetVersions(cognateStore).ListVersions(ctx)
+		if err != nil {
+			return err
+		}
+		languages, err := cognateStore.ListLanguages(ctx)
+		if err != nil {
+			return err
+		}
+
+		status := struct {
+			ActiveVersion int64                  `json:"active_version"`
+			Languages     int                    `json:"languages"`
+			Versions      []model.DatasetVersion `json:"versions"`
+		}{Languages: len(languages), Versions: versions}
+		for _, version := range versions {
+			if version.Active {
+				status.ActiveVersion = version.Version
+			}
+		}
+		return printJSON(status)
+	})
+}
+
+func clearCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("clear", flag.ContinueOnError)
+	scopeName := flags.String("scope", "all", "all, cognates, languages or concepts")
+	confirmed := flags.Bool("yes", false, "confirm the delete")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	scope, err := service.ParseClearScope(*scopeName)
+	if err != nil {
+		return err
+	}
+	if !*confirmed {
+		return fmt.Errorf("clearing %s data cannot be undone, repeat with -yes to confirm", scope)
+	}
+
+	return withStore(func(cognateStore store.CognateStore) error {
+		importer := newImporter(cognateStore)
+		token, err := importer.PrepareClear(ctx, scope)
+		if err != nil {
+			return err
+		}
+
+		deleted, err := importer.ClearDatabase(ctx, scope, token)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Deleted %d entries\n", deleted)
+		return nil
+	})
+}
+
+func exportCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("o", "-", "output file, - for stdout")
+	formatName := flags.String("format", "tsv", "tsv, jsonl or csv")
+	langs := flags.String("langs", "", "a language or a comma-separated pair of languages")
+	conceptPrefix := flags.String("concept-prefix", "", "concept ID prefix")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	format, err := service.ParseExportFormat(*formatName)
+	if err != nil {
+		return err
+	}
+	opts := service.ExportOptions{Format: format, ConceptPrefix: *conceptPrefix}
+	if *langs != "" {
+		opts.Langs = strings.Split(*langs, ",")
+	}
+	if err := service.ValidateExportOptions(opts); err != nil {
+		return err
+	}
+
+	export := func(out io.Writer) error {
+		return withStore(func(cognateStore store.CognateStore) error {
+			rows, err := service.NewDatasetExporter(cognateStore).Export(ctx, out, opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Exported %d rows\n", rows)
+			return nil
+		})
+	}
+
+	// Stdout may be a pipe, which cannot be synced
+	if *output == "-" {
+		return export(os.Stdout)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := export(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
@@ -13,9 +15,11 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/redis/go-redis/v9"
 
+	"cognet-world-inquiry-service/internal/auth"
 	"cognet-world-inquiry-service/internal/config"
 	"cognet-world-inquiry-service/internal/handler"
 	"cognet-world-inquiry-service/internal/service"
+	"cognet-world-inquiry-service/internal/store"
 )
 
 func main() {
@@ -24,27 +28,75 @@ func main() {
 		log.Fatal("Failed to load configuration:", err)
 	}
 
-	// Initialize Redis client
-	redisClient := redis.NewClient(&redis.Options{
-		Addr:     config.AppConfig.RedisAddress,
-		Password: config.AppConfig.RedisPassword,
-	})
+	// Without a subcommand the service starts the HTTP server
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	if command == "serve" {
+		serve()
+		return
+	}
+
+	if err := runCommand(command, args); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// serve runs the HTTP server until it is interrupted
+func serve() {
+	// Initialize storage
+	cognateStore, err := newStore(config.AppConfig)
+	if err != nil {
+		log.Fatal("Failed to initialize storage:", err)
+	}
+	defer cognateStore.Close()
 
-	// Verify Redis connection
-	if err := redisClient.Ping(context.Background()).Err(); err != nil {
-		log.Fatal("Failed to connect to Redis:", err)
+	// Load language metadata and follow changes made by other replicas
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+
+	languageRegistry := service.NewLanguageRegistry(cognateStore)
+	if err := languageRegistry.Load(watchCtx); err != nil {
+		log.Fatal("Failed to load languages:", err)
 	}
-	defer redisClient.Close()
+	go languageRegistry.Watch(watchCtx)
 
 	// Initialize services
-	dataImporter := service.NewDataImporter(redisClient)
-	cognateSearchService := service.NewCognateSearch(redisClient)
+	dataImporter := service.NewDataImporter(cognateStore, languageRegistry)
+	cognateSearchService := service.NewCognateSearch(cognateStore, languageRegistry)
+	datasetVersions := service.NewDatasetVersions(cognateStore)
+	languageCatalog := service.NewLanguageCatalog(cognateStore, languageRegistry)
+	datasetExporter := service.NewDatasetExporter(cognateStore)
 
 	// Initialize handlers
 	importHandler := handler.NewImportHandler(dataImporter)
 
 	cognateHandler := handler.NewCognateHandler(cognateSearchService)
 
+	datasetHandler := handler.NewDatasetHandler(datasetVersions)
+
+	languageHandler := handler.NewLanguageHandler(languageCatalog)
+
+	exportHandler := handler.NewExportHandler(datasetExporter)
+
+	// Initialize authentication
+	authenticator, err := auth.NewAuthenticator(config.AppConfig.APIKeys, config.AppConfig.JWTSecret, config.AppConfig.JWTIssuer, config.AppConfig.JWTMaxLifetime)
+	if err != nil {
+		log.Fatal("Failed to configure authentication:", err)
+	}
+	if config.AppConfig.AuthDisabled {
+		log.Println("WARNING: authentication is disabled")
+	} else if !authenticator.HasCredentials() {
+		// Every route needs a role, the server would reject every request
+		log.Fatal("No API_KEYS or JWT_SECRET configured: set one of them, or AUTH_DISABLED=true for local development")
+	}
+
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
 		ErrorHandler:      handler.ErrorHandler,
@@ -60,12 +112,12 @@ func main() {
 		Format: "[${time}] ${status} - ${method} ${path}\n",
 	}))
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowHeaders: "Origin, Content-Type, Accept",
+		AllowOrigins: config.AppConfig.CORSAllowOrigins,
+		AllowHeaders: "Origin, Content-Type, Accept, Authorization, X-API-Key",
 	}))
 
 	// Setup routes
-	setupRoutes(app, importHandler, cognateHandler)
+	setupRoutes(app, authenticator, importHandler, cognateHandler, datasetHandler, languageHandler, exportHandler)
 
 	// Graceful shutdown channel
 	shutdownChan := make(chan os.Signal, 1)
@@ -90,22 +142,74 @@ func main() {
 	}
 }
 
-func setupRoutes(app *fiber.App, importHandler *handler.ImportHandler, cognateHandler *handler.CognateHandler) {
+// newStore opens the storage backend selected in the configuration
+func newStore(cfg config.Config) (store.CognateStore, error) {
+	switch cfg.StorageBackend {
+	case "redis":
+		redisClient := redis.NewClient(&redis.Options{
+			Addr:     cfg.RedisAddress,
+			Password: cfg.RedisPassword,
+		})
+
+		// Verify Redis connection
+		if err := redisClient.Ping(context.Background()).Err(); err != nil {
+			redisClient.Close()
+			return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+		}
+		return store.NewRedisStore(redisClient), nil
+	case "embedded":
+		return store.NewBoltStore(cfg.EmbeddedDBPath)
+	case "memory":
+		log.Println("WARNING: using the in-memory store, data is lost on shutdown")
+		return store.NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q, expected redis, embedded or memory", cfg.StorageBackend)
+	}
+}
+
+func setupRoutes(app *fiber.App, authenticator *auth.Authenticator, importHandler *handler.ImportHandler, cognateHandler *handler.CognateHandler, datasetHandler *handler.DatasetHandler, languageHandler *handler.LanguageHandler, exportHandler *handler.ExportHandler) {
 	api := app.Group("/api/v1")
 
+	requireReader := handler.RequireRole(authenticator, auth.RoleReader)
+	requireAdmin := handler.RequireRole(authenticator, auth.RoleAdmin)
+	if config.AppConfig.AuthDisabled {
+		requireReader = func(c *fiber.Ctx) error { return c.Next() }
+		requireAdmin = requireReader
+	}
+
 	// Import routes
-	importRoutes := api.Group("/import")
+	importRoutes := api.Group("/import", requireAdmin)
 	importRoutes.Post("/tsv", importHandler.ImportTSV)
 	importRoutes.Post("/languages", importHandler.ImportLanguages)
+	importRoutes.Post("/concepts", importHandler.ImportConcepts)
 	importRoutes.Get("/status", importHandler.GetStatus)
+	importRoutes.Get("/jobs/:id", importHandler.GetImportJob)
 	importRoutes.Delete("/clear", importHandler.ClearDatabase)
+	importRoutes.Get("/versions", datasetHandler.ListVersions)
+	importRoutes.Post("/versions/gc", datasetHandler.GarbageCollect)
+	importRoutes.Post("/versions/:version/activate", datasetHandler.ActivateVersion)
 
 	// Search routes
-	searchRoutes := api.Group("/search")
+	searchRoutes := api.Group("/search", requireReader)
 	searchRoutes.Get("/suggestions", cognateHandler.GetSuggestions)
 	searchRoutes.Get("/concept/:id", cognateHandler.GetByConceptID)
+	searchRoutes.Get("/concept/:id/geo", cognateHandler.GetConceptGeo)
+	searchRoutes.Get("/concepts", cognateHandler.SearchConcepts)
 	searchRoutes.Get("/chains/concept/:id", cognateHandler.FindCognateChains)
-
+	searchRoutes.Get("/word/:word", cognateHandler.GetByWord)
+	searchRoutes.Get("/word/:word/graph", cognateHandler.GetWordGraph)
+	searchRoutes.Get("/path", cognateHandler.FindPath)
+
+	// Language routes
+	languageRoutes := api.Group("/languages", requireReader)
+	languageRoutes.Get("/", languageHandler.ListLanguages)
+	languageRoutes.Get("/missing", languageHandler.GetMissingLanguages)
+	languageRoutes.Get("/:code", languageHandler.GetLanguage)
+	languageRoutes.Put("/:code", requireAdmin, languageHandler.PutLanguage)
+	languageRoutes.Delete("/:code", requireAdmin, languageHandler.DeleteLanguage)
+
+	// Export routes
+	api.Get("/export", requireAdmin, exportHandler.Export)
 }
 
 func init() {
@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+)
+
+type Role string
+
+const (
+	RoleReader Role = "reader"
+	RoleAdmin  Role = "admin"
+)
+
+// roleRank orders roles, a role grants everything a lower ranked one does
+var roleRank = map[Role]int{
+	RoleReader: 1,
+	RoleAdmin:  2,
+}
+
+var (
+	ErrMissingCredentials = errors.New("missing credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// ParseRole validates a role name
+func ParseRole(name string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := roleRank[role]; !ok {
+		return "", fmt.Errorf("unknown role %q, expected reader or admin", name)
+	}
+	return role, nil
+}
+
+// Allows reports whether the role grants access to routes requiring required
+func (r Role) Allows(required Role) bool {
+	return roleRank[r] >= roleRank[required]
+}
+
+// Principal is the authenticated caller
+type Principal struct {
+	Subject string
+	Role    Role
+}
+
+type Authenticator struct {
+	apiKeys        map[[sha256.Size]byte]Role // keyed by the hash of the API key
+	jwtSecret      []byte
+	jwtIssuer      string
+	jwtMaxLifetime time.Duration
+	now            func() time.Time
+}
+
+// NewAuthenticator builds an authenticator from API keys (key to role name)
+// and an optional HMAC secret for JWTs. An empty issuer accepts any issuer,
+// a zero max lifetime accepts any expiry.
+func NewAuthenticator(apiKeys map[string]string, jwtSecret, jwtIssuer string, jwtMaxLifetime time.Duration) (*Authenticator, error) {
+	a := &Authenticator{
+		apiKeys:        make(map[[sha256.Size]byte]Role, len(apiKeys)),
+		jwtSecret:      []byte(jwtSecret),
+		jwtIssuer:      jwtIssuer,
+		jwtMaxLifetime: jwtMaxLifetime,
+		now:            time.Now,
+	}
+
+	for key, roleName := range apiKeys {
+		role, err := ParseRole(roleName)
+		if err != nil {
+			return nil, fmt.Errorf("api key %s...: %w", key[:min(4, len(key))], err)
+		}
+		a.apiKeys[sha256.Sum256([]byte(key))] = role
+	}
+
+	return a, nil
+}
+
+// HasCredentials reports whether any API key or JWT secret is configured
+func (a *Authenticator) HasCredentials() bool {
+	return len(a.apiKeys) > 0 || len(a.jwtSecret) > 0
+}
+
+// Authenticate resolves an API key or a bearer token (API key or JWT)
+func (a *Authenticator) Authenticate(apiKey, bearer string) (*Principal, error) {
+	if apiKey == "" && bearer == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	for _, candidate := range []string{apiKey, bearer} {
+		if candidate == "" {
+			continue
+		}
+		// Map lookup on a hash instead of comparing raw keys
+		if role, ok := a.apiKeys[sha256.Sum256([]byte(candidate))]; ok {
+			return &Principal{Subject: "api-key", Role: role}, nil
+		}
+	}
+
+	if bearer != "" && len(a.jwtSecret) > 0 {
+		return a.verifyJWT(bearer)
+	}
+
+	return nil, ErrInvalidCredentials
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+	Role      string   `json:"role"`
+	Roles     []string `json:"roles"`
+}
+
+// verifyJWT checks an HS256/HS384/HS512 signed token and picks the highest
+// role found in its role or roles claims. Tokens must expire, and not later
+// than the max lifetime from now when one is set.
+func (a *Authenticator) verifyJWT(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidCredentials
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	var newHash func() hash.Hash
+	switch header.Alg {
+	case "HS256":
+		newHash = sha256.New
+	case "HS384":
+		newHash = sha512.New384
+	case "HS512":
+		newHash = sha512.New
+	default:
+		// Also rejects "none"
+		return nil, ErrInvalidCredentials
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	mac := hmac.New(newHash, a.jwtSecret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, ErrInvalidCredentials
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	now := a.now().Unix()
+	if claims.ExpiresAt == nil || now >= *claims.ExpiresAt {
+		return nil, ErrInvalidCredentials
+	}
+	if a.jwtMaxLifetime > 0 && *claims.ExpiresAt-now > int64(a.jwtMaxLifetime/time.Second) {
+		return nil, ErrInvalidCredentials
+	}
+	if claims.NotBefore != nil && now < *claims.NotBefore {
+		return nil, ErrInvalidCredentials
+	}
+	if a.jwtIssuer != "" && claims.Issuer != a.jwtIssuer {
+		return nil, ErrInvalidCredentials
+	}
+
+	var best Role
+	for _, name := range append(claims.Roles, claims.Role) {
+		if role, err := ParseRole(name); err == nil && roleRank[role] > roleRank[best] {
+			best = role
+		}
+	}
+	if best == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Principal{Subject: claims.Subject, Role: best}, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
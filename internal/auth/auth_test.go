@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"hash"
+	"testing"
+	"time"
+)
+
+const testSecret = "s3cret"
+
+var testNow = time.Unix(1_700_000_000, 0)
+
+// signJWT builds a token signed with secret using alg
+func signJWT(t *testing.T, alg, secret string, claims map[string]interface{}) string {
+	t.Helper()
+
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	newHash := map[string]func() hash.Hash{
+		"HS256": sha256.New,
+		"HS384": sha512.New384,
+		"HS512": sha512.New,
+		"none":  sha256.New,
+	}[alg]
+	unsigned := encode(map[string]string{"alg": alg, "typ": "JWT"}) + "." + encode(claims)
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newTestAuthenticator(t *testing.T, issuer string, maxLifetime time.Duration) *Authenticator {
+	t.Helper()
+
+	a, err := NewAuthenticator(map[string]string{"reader-key": "reader", "admin-key": "Admin"}, testSecret, issuer, maxLifetime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.now = func() time.Time { return testNow }
+	return a
+}
+
+func TestVerifyJWT(t *testing.T) {
+	exp := testNow.Add(time.Hour).Unix()
+	tests := []struct {
+		name        string
+		alg         string
+		secret      string
+		issuer      string
+		maxLifetime time.Duration
+		claims      map[string]interface{}
+		want        Role
+	}{
+		{name: "HS256", alg: "HS256", claims: map[string]interface{}{"exp": exp, "role": "reader"}, want: RoleReader},
+		{name: "HS384", alg: "HS384", claims: map[string]interface{}{"exp": exp, "role": "admin"}, want: RoleAdmin},
+		{name: "HS512", alg: "HS512", claims: map[string]interface{}{"exp": exp, "role": "reader"}, want: RoleReader},
+		{name: "highest of roles", alg: "HS256", claims: map[string]interface{}{"exp": exp, "roles": []string{"reader", "admin", "owner"}}, want: RoleAdmin},
+		{name: "matching issuer", alg: "HS256", issuer: "cognet", claims: map[string]interface{}{"exp": exp, "iss": "cognet", "role": "reader"}, want: RoleReader},
+		{name: "within max lifetime", alg: "HS256", maxLifetime: time.Hour, claims: map[string]interface{}{"exp": exp, "role": "reader"}, want: RoleReader},
+		{name: "bad signature", alg: "HS256", secret: "other", claims: map[string]interface{}{"exp": exp, "role": "admin"}},
+		{name: "unsupported algorithm", alg: "none", claims: map[string]interface{}{"exp": exp, "role": "admin"}},
+		{name: "wrong issuer", alg: "HS256", issuer: "cognet", claims: map[string]interface{}{"exp": exp, "iss": "other", "role": "reader"}},
+		{name: "missing issuer", alg: "HS256", issuer: "cognet", claims: map[string]interface{}{"exp": exp, "role": "reader"}},
+		{name: "no expiry", alg: "HS256", claims: map[string]interface{}{"role": "reader"}},
+		{name: "expired", alg: "HS256", claims: map[string]interface{}{"exp": testNow.Unix(), "role": "reader"}},
+		{name: "beyond max lifetime", alg: "HS256", maxLifetime: time.Minute, claims: map[string]interface{}{"exp": exp, "role": "reader"}},
+		{name: "not yet valid", alg: "HS256", claims: map[string]interface{}{"exp": exp, "nbf": exp - 1, "role": "reader"}},
+		{name: "no known role", alg: "HS256", claims: map[string]interface{}{"exp": exp, "role": "owner"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := tt.secret
+			if secret == "" {
+				secret = testSecret
+			}
+			a := newTestAuthenticator(t, tt.issuer, tt.maxLifetime)
+
+			principal, err := a.Authenticate("", signJWT(t, tt.alg, secret, tt.claims))
+			if tt.want == "" {
+				if !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf("Authenticate() error = %v, want ErrInvalidCredentials", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if principal.Role != tt.want {
+				t.Errorf("role = %q, want %q", principal.Role, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		apiKey  string
+		bearer  string
+		want    Role
+		wantErr error
+	}{
+		{name: "header key", apiKey: "reader-key", want: RoleReader},
+		{name: "bearer key", bearer: "admin-key", want: RoleAdmin},
+		{name: "unknown key", apiKey: "other-key", wantErr: ErrInvalidCredentials},
+		{name: "malformed bearer", bearer: "not.a.jwt.at.all", wantErr: ErrInvalidCredentials},
+		{name: "no credentials", wantErr: ErrMissingCredentials},
+	}
+
+	a := newTestAuthenticator(t, "", 0)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := a.Authenticate(tt.apiKey, tt.bearer)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if principal.Role != tt.want {
+				t.Errorf("role = %q, want %q", principal.Role, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoleAllows(t *testing.T) {
+	tests := []struct {
+		role     Role
+		required Role
+		want     bool
+	}{
+		{RoleReader, RoleReader, true},
+		{RoleReader, RoleAdmin, false},
+		{RoleAdmin, RoleReader, true},
+		{RoleAdmin, RoleAdmin, true},
+		{"", RoleReader, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.role.Allows(tt.required); got != tt.want {
+			t.Errorf("%q.Allows(%q) = %v, want %v", tt.role, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestNewAuthenticatorRejectsUnknownRole(t *testing.T) {
+	if _, err := NewAuthenticator(map[string]string{"key": "owner"}, "", "", 0); err == nil {
+		t.Fatal("NewAuthenticator() accepted an unknown role")
+	}
+}
@@ -2,7 +2,10 @@ package config
 
 import (
 	"fmt"
+	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,6 +14,22 @@ type Config struct {
 	RedisAddress  string
 	RedisPassword string
 	ServerPort    string
+	// StorageBackend selects where data is kept: redis, embedded or memory
+	StorageBackend string
+	// EmbeddedDBPath is the database file of the embedded backend
+	EmbeddedDBPath string
+
+	// AuthDisabled turns off authentication, for local development only
+	AuthDisabled bool
+	// APIKeys maps static API keys to their role, from API_KEYS=key:role,...
+	APIKeys   map[string]string
+	JWTSecret string
+	JWTIssuer string
+	// JWTMaxLifetime rejects JWTs expiring further in the future, 0 for no
+	// limit
+	JWTMaxLifetime time.Duration
+
+	CORSAllowOrigins string
 }
 
 var AppConfig Config
@@ -18,14 +37,60 @@ var AppConfig Config
 func Load() error {
 	// Load .env file if it exists
 	if err := godotenv.Load(); err != nil {
-		fmt.Println("No .env file found")
+		log.Println("No .env file found")
+	}
+
+	apiKeys, err := parseAPIKeys(os.Getenv("API_KEYS"))
+	if err != nil {
+		return err
+	}
+
+	var jwtMaxLifetime time.Duration
+	if raw := os.Getenv("JWT_MAX_LIFETIME"); raw != "" {
+		jwtMaxLifetime, err = time.ParseDuration(raw)
+		if err != nil || jwtMaxLifetime < 0 {
+			return fmt.Errorf("invalid JWT_MAX_LIFETIME %q, expected a duration such as 24h", raw)
+		}
 	}
 
 	AppConfig = Config{
-		RedisAddress:  os.Getenv("REDIS_ADDRESS"),
-		RedisPassword: os.Getenv("REDIS_PASSWORD"),
-		ServerPort:    os.Getenv("SERVER_PORT"),
+		RedisAddress:     os.Getenv("REDIS_ADDRESS"),
+		RedisPassword:    os.Getenv("REDIS_PASSWORD"),
+		ServerPort:       os.Getenv("SERVER_PORT"),
+		StorageBackend:   getEnvDefault("STORAGE_BACKEND", "redis"),
+		EmbeddedDBPath:   getEnvDefault("EMBEDDED_DB_PATH", "cognet.db"),
+		AuthDisabled:     os.Getenv("AUTH_DISABLED") == "true",
+		APIKeys:          apiKeys,
+		JWTSecret:        os.Getenv("JWT_SECRET"),
+		JWTIssuer:        os.Getenv("JWT_ISSUER"),
+		JWTMaxLifetime:   jwtMaxLifetime,
+		CORSAllowOrigins: getEnvDefault("CORS_ALLOW_ORIGINS", "*"),
 	}
-	fmt.Println("Configuration loaded successfully")
+	log.Println("Configuration loaded successfully")
 	return nil
 }
+
+func getEnvDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// parseAPIKeys reads a comma-separated list of key:role pairs
+func parseAPIKeys(raw string) (map[string]string, error) {
+	apiKeys := make(map[string]string)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, role, ok := strings.Cut(entry, ":")
+		if !ok || key == "" || role == "" {
+			return nil, fmt.Errorf("invalid API_KEYS entry, expected key:role")
+		}
+		apiKeys[key] = role
+	}
+	return apiKeys, nil
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"cognet-world-inquiry-service/internal/auth"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// principalKey is the c.Locals key of the authenticated *auth.Principal
+const principalKey = "principal"
+
+// RequireRole rejects requests without credentials granting role. Failures
+// are returned as fiber errors so ErrorHandler renders them as JSON.
+func RequireRole(authenticator *auth.Authenticator, role auth.Role) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		bearer := ""
+		if header := c.Get(fiber.HeaderAuthorization); len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+			bearer = strings.TrimSpace(header[7:])
+		}
+
+		principal, err := authenticator.Authenticate(c.Get("X-API-Key"), bearer)
+		if err != nil {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="cognet"`)
+			if errors.Is(err, auth.ErrMissingCredentials) {
+				return fiber.NewError(fiber.StatusUnauthorized, "authentication required")
+			}
+			return fiber.NewError(fiber.StatusUnauthorized, "invalid credentials")
+		}
+
+		if !principal.Role.Allows(role) {
+			return fiber.NewError(fiber.StatusForbidden, "requires "+string(role)+" role")
+		}
+
+		c.Locals(principalKey, principal)
+		return c.Next()
+	}
+}
@@ -1,7 +1,12 @@
 package handler
 
 import (
+	"cognet-world-inquiry-service/internal/model"
 	"cognet-world-inquiry-service/internal/service"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -25,16 +30,58 @@ func (h *CognateHandler) GetSuggestions(c *fiber.Ctx) error {
 		})
 	}
 
-	suggestions, err := h.cognateSearch.GetWordSuggestions(c.Context(), prefix)
+	opts := service.SuggestionOptions{
+		Limit:        c.QueryInt("limit", service.DefaultSuggestionLimit),
+		Cursor:       int64(c.QueryInt("cursor", 0)),
+		Langs:        queryList(c, "lang"),
+		ExcludeLangs: queryList(c, "exclude_lang"),
+	}
+	for _, code := range append(append([]string{}, opts.Langs...), opts.ExcludeLangs...) {
+		if !service.IsLanguageCode(code) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("invalid language code %q, expected ISO 639-3", code),
+			})
+		}
+	}
+	if opts.Limit < 1 || opts.Limit > service.MaxSuggestionLimit {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("limit must be between 1 and %d", service.MaxSuggestionLimit),
+		})
+	}
+	if opts.Cursor < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "cursor must not be negative",
+		})
+	}
+
+	page, err := h.cognateSearch.GetWordSuggestions(c.Context(), prefix, opts)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	return c.JSON(fiber.Map{
-		"data": suggestions,
-	})
+	return c.JSON(page)
+}
+
+// geoJSONContentType is the media type of GeoJSON (RFC 7946)
+const geoJSONContentType = "application/geo+json"
+
+// wantsGeoJSON reads the format parameter of the chain and concept routes
+func wantsGeoJSON(c *fiber.Ctx) (bool, error) {
+	switch format := c.Query("format"); format {
+	case "", "json":
+		return false, nil
+	case "geojson":
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid format %q, expected json or geojson", format)
+	}
+}
+
+// sendGeoJSON writes the chains of a concept as a FeatureCollection
+func sendGeoJSON(c *fiber.Ctx, chains *model.CognateChainResponse) error {
+	return c.JSON(service.ChainsGeoJSON(chains), geoJSONContentType)
 }
 
 // GetByConceptID handles getting cognates by concept ID
@@ -46,16 +93,30 @@ func (h *CognateHandler) GetByConceptID(c *fiber.Ctx) error {
 		})
 	}
 
-	cognates, err := h.cognateSearch.FindByConceptID(c.Context(), conceptID)
+	geoJSON, err := wantsGeoJSON(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if geoJSON {
+		chains, err := h.cognateSearch.FindCognateChains(c.Context(), conceptID, service.ChainOptions{})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return sendGeoJSON(c, chains)
+	}
+
+	response, err := h.cognateSearch.FindByConceptID(c.Context(), conceptID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	return c.JSON(fiber.Map{
-		"data": cognates,
-	})
+	return c.JSON(response)
 }
 
 func (h *CognateHandler) FindCognateChains(c *fiber.Ctx) error {
@@ -67,17 +128,180 @@ func (h *CognateHandler) FindCognateChains(c *fiber.Ctx) error {
 	}
 
 	// Get optional word and language parameters
-	word := c.Query("word")
+	opts := service.ChainOptions{
+		Word:  c.Query("word"),
+		Lang:  c.Query("lang"),
+		Stats: c.QueryBool("stats"),
+	}
+
+	geoJSON, err := wantsGeoJSON(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	cognates, err := h.cognateSearch.FindCognateChains(c.Context(), conceptID, opts)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if geoJSON {
+		return sendGeoJSON(c, cognates)
+	}
+
+	return c.JSON(model.DataResponse{
+		Data:             cognates,
+		MissingLanguages: cognates.MissingLanguages,
+	})
+}
+
+// SearchConcepts handles full-text search over concept lemmas and glosses
+func (h *CognateHandler) SearchConcepts(c *fiber.Ctx) error {
+	query := c.Query("q")
+	if strings.TrimSpace(query) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "q is required",
+		})
+	}
+
+	opts := service.ConceptSearchOptions{
+		Limit:  c.QueryInt("limit", service.DefaultSuggestionLimit),
+		Cursor: int64(c.QueryInt("cursor", 0)),
+	}
+	if opts.Limit < 1 || opts.Limit > service.MaxSuggestionLimit {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("limit must be between 1 and %d", service.MaxSuggestionLimit),
+		})
+	}
+	if opts.Cursor < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "cursor must not be negative",
+		})
+	}
+
+	page, err := h.cognateSearch.SearchConcepts(c.Context(), query, opts)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(page)
+}
+
+// GetWordGraph handles the cognates of a word across all of its concepts
+func (h *CognateHandler) GetWordGraph(c *fiber.Ctx) error {
+	word, err := url.PathUnescape(c.Params("word"))
+	if err != nil || word == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "word is required",
+		})
+	}
+
 	lang := c.Query("lang")
+	if !service.IsLanguageCode(lang) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "lang is required, expected an ISO 639-3 code",
+		})
+	}
+
+	depth := c.QueryInt("depth", service.DefaultWordGraphDepth)
+	if depth < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "depth must be at least 1",
+		})
+	}
+
+	response, err := h.cognateSearch.FindWordGraph(c.Context(), word, lang, depth)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(model.DataResponse{
+		Data:             response,
+		MissingLanguages: response.MissingLanguages,
+	})
+}
+
+// FindPath handles shortest cognate paths between two words of a concept
+func (h *CognateHandler) FindPath(c *fiber.Ctx) error {
+	conceptID := c.Query("concept")
+	fromLang, fromWord := c.Query("from_lang"), c.Query("from_word")
+	toLang, toWord := c.Query("to_lang"), c.Query("to_word")
+	if conceptID == "" || fromLang == "" || fromWord == "" || toLang == "" || toWord == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "concept, from_lang, from_word, to_lang and to_word are required",
+		})
+	}
+	for _, code := range []string{fromLang, toLang} {
+		if !service.IsLanguageCode(code) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("invalid language code %q, expected ISO 639-3", code),
+			})
+		}
+	}
+
+	response, err := h.cognateSearch.FindPath(c.Context(), conceptID, fromLang, fromWord, toLang, toWord)
+	if errors.Is(err, service.ErrWordNotInConcept) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(model.DataResponse{
+		Data:             response,
+		MissingLanguages: response.MissingLanguages,
+	})
+}
+
+// GetConceptGeo handles the geographic spread of a concept's cognates
+func (h *CognateHandler) GetConceptGeo(c *fiber.Ctx) error {
+	conceptID := c.Params("id")
+	if conceptID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "concept ID is required",
+		})
+	}
 
-	cognates, err := h.cognateSearch.FindCognateChains(c.Context(), conceptID, word, lang)
+	response, err := h.cognateSearch.FindConceptGeo(c.Context(), conceptID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	return c.JSON(fiber.Map{
-		"data": cognates,
+	return c.JSON(model.DataResponse{
+		Data:             response,
+		MissingLanguages: response.MissingLanguages,
 	})
 }
+
+// GetByWord handles exact word lookups across every concept the word belongs to
+func (h *CognateHandler) GetByWord(c *fiber.Ctx) error {
+	word, err := url.PathUnescape(c.Params("word"))
+	if err != nil || word == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "word is required",
+		})
+	}
+
+	lang := c.Query("lang")
+
+	results, err := h.cognateSearch.FindByWord(c.Context(), word, lang)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(results)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"cognet-world-inquiry-service/internal/service"
+	"cognet-world-inquiry-service/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// newTestApp serves the search routes over a memory store holding rows
+func newTestApp(t *testing.T, rows string) *fiber.App {
+	t.Helper()
+
+	cognateStore := store.NewMemoryStore()
+	languages := service.NewLanguageRegistry(cognateStore)
+	importer := service.NewDataImporter(cognateStore, languages)
+	header := "concept_id\tlang1\tword1\tlang2\tword2\n"
+	if _, err := importer.ImportFromReader(context.Background(), strings.NewReader(header+rows), service.ImportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewCognateHandler(service.NewCognateSearch(cognateStore, languages))
+	app := fiber.New()
+	app.Get("/search/path", h.FindPath)
+	return app
+}
+
+func TestFindPathStatus(t *testing.T) {
+	app := newTestApp(t, "n00000001\teng\tbank\tdeu\tbank\n"+
+		"n00000001\tdeu\tbank\tfra\tbanque\n"+
+		"n00000001\ttur\tbalık\taze\tbalıq\n")
+
+	tests := []struct {
+		name      string
+		query     url.Values
+		status    int
+		connected bool
+	}{
+		{
+			name:   "connected",
+			query:  url.Values{"concept": {"n00000001"}, "from_lang": {"eng"}, "from_word": {"bank"}, "to_lang": {"fra"}, "to_word": {"banque"}},
+			status: fiber.StatusOK, connected: true,
+		},
+		{
+			name:   "not connected",
+			query:  url.Values{"concept": {"n00000001"}, "from_lang": {"eng"}, "from_word": {"bank"}, "to_lang": {"tur"}, "to_word": {"balık"}},
+			status: fiber.StatusOK,
+		},
+		{
+			name:   "word not in concept",
+			query:  url.Values{"concept": {"n00000001"}, "from_lang": {"eng"}, "from_word": {"shore"}, "to_lang": {"fra"}, "to_word": {"banque"}},
+			status: fiber.StatusNotFound,
+		},
+		{
+			name:   "unknown concept",
+			query:  url.Values{"concept": {"n99999999"}, "from_lang": {"eng"}, "from_word": {"bank"}, "to_lang": {"fra"}, "to_word": {"banque"}},
+			status: fiber.StatusNotFound,
+		},
+		{
+			name:   "missing word",
+			query:  url.Values{"concept": {"n00000001"}, "from_lang": {"eng"}, "to_lang": {"fra"}, "to_word": {"banque"}},
+			status: fiber.StatusBadRequest,
+		},
+		{
+			name:   "invalid language",
+			query:  url.Values{"concept": {"n00000001"}, "from_lang": {"english"}, "from_word": {"bank"}, "to_lang": {"fra"}, "to_word": {"banque"}},
+			status: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", "/search/path?"+tt.query.Encode(), nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.status != fiber.StatusOK {
+				return
+			}
+
+			var body struct {
+				Data struct {
+					Connected bool `json:"connected"`
+				} `json:"data"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Data.Connected != tt.connected {
+				t.Errorf("connected = %v, want %v", body.Data.Connected, tt.connected)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"cognet-world-inquiry-service/internal/service"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DatasetHandler struct {
+	datasetVersions service.DatasetVersions
+}
+
+func NewDatasetHandler(datasetVersions service.DatasetVersions) *DatasetHandler {
+	return &DatasetHandler{
+		datasetVersions: datasetVersions,
+	}
+}
+
+// ListVersions returns every dataset version, newest first
+func (h *DatasetHandler) ListVersions(c *fiber.Ctx) error {
+	versions, err := h.datasetVersions.ListVersions(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": versions,
+	})
+}
+
+// ActivateVersion switches searches to a completed dataset version
+func (h *DatasetHandler) ActivateVersion(c *fiber.Ctx) error {
+	version, err := strconv.ParseInt(c.Params("version"), 10, 64)
+	if err != nil || version < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "version must be a positive number",
+		})
+	}
+
+	err = h.datasetVersions.ActivateVersion(c.Context(), version)
+	switch {
+	case errors.Is(err, service.ErrVersionNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrVersionNotReady):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Dataset version activated",
+		"version": version,
+	})
+}
+
+// GarbageCollect deletes old dataset versions, keeping the active one and
+// the newest keep ready versions for rollback
+func (h *DatasetHandler) GarbageCollect(c *fiber.Ctx) error {
+	keep := c.QueryInt("keep", 1)
+	if keep < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "keep must not be negative",
+		})
+	}
+
+	deleted, err := h.datasetVersions.GarbageCollect(c.Context(), keep)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   err.Error(),
+			"deleted": deleted,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Old dataset versions deleted",
+		"deleted": deleted,
+	})
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"bufio"
+	"cognet-world-inquiry-service/internal/service"
+	"context"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+type ExportHandler struct {
+	datasetExporter service.DatasetExporter
+}
+
+func NewExportHandler(datasetExporter service.DatasetExporter) *ExportHandler {
+	return &ExportHandler{
+		datasetExporter: datasetExporter,
+	}
+}
+
+var exportContentTypes = map[service.ExportFormat]string{
+	service.ExportFormatTSV:   "text/tab-separated-values; charset=utf-8",
+	service.ExportFormatCSV:   "text/csv; charset=utf-8",
+	service.ExportFormatJSONL: "application/x-ndjson",
+}
+
+// flushWriter hands every write on to the connection, so a large export is
+// sent in chunks and a client that went away stops it at the next write
+type flushWriter struct {
+	w *bufio.Writer
+}
+
+func (fw flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	if err == nil {
+		err = fw.w.Flush()
+	}
+	return n, err
+}
+
+// Export streams the active dataset with chunked transfer encoding. Errors
+// after the first byte can only end the response early, they are logged.
+func (h *ExportHandler) Export(c *fiber.Ctx) error {
+	format, err := service.ParseExportFormat(c.Query("format"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// The options outlive the request handler, so they must not share the
+	// request buffer
+	opts := service.ExportOptions{
+		Format:        format,
+		ConceptPrefix: utils.CopyString(c.Query("concept_prefix")),
+		Langs:         queryList(c, "langs"),
+	}
+	if err := service.ValidateExportOptions(opts); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Attachment guesses the type from the extension, set the exact one after it
+	c.Attachment("cognet-export." + string(format))
+	c.Set(fiber.HeaderContentType, exportContentTypes[format])
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		rows, err := h.datasetExporter.Export(context.Background(), flushWriter{w: w}, opts)
+		if err != nil {
+			log.Printf("export after %d rows: %v", rows, err)
+		}
+	})
+	return nil
+}
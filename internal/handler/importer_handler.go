@@ -3,6 +3,7 @@ package handler
 import (
 	"bufio"
 	"cognet-world-inquiry-service/internal/service"
+	"errors"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -17,7 +18,17 @@ func NewImportHandler(dataImporter service.DataImporter) *ImportHandler {
 	}
 }
 
+// ImportTSV stores the uploaded file and imports it as a background job. The
+// response only carries the job, progress is polled from GetImportJob.
 func (h *ImportHandler) ImportTSV(c *fiber.Ctx) error {
+	mode, err := service.ParseImportMode(c.Query("mode"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	dryRun := c.QueryBool("dry_run")
+
 	// Get the file from form data
 	file, err := c.FormFile("file")
 	if err != nil {
@@ -26,27 +37,49 @@ func (h *ImportHandler) ImportTSV(c *fiber.Ctx) error {
 		})
 	}
 
-	// Open the uploaded file
-	uploadedFile, err := file.Open()
+	// The upload is released once the request ends, keep a copy for the job
+	source, err := spoolUpload(file)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to open uploaded file: " + err.Error(),
+			"error": "Failed to store uploaded file: " + err.Error(),
 		})
 	}
-	defer uploadedFile.Close()
 
-	// Use a buffered reader
-	reader := bufio.NewReaderSize(uploadedFile, 1024*1024) // 1MB buffer
+	job, err := h.dataImporter.StartImportJob(c.Context(), source, file.Size, service.ImportOptions{Mode: mode, DryRun: dryRun})
+	if errors.Is(err, service.ErrImportInProgress) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 
-	// Start the import process
-	if err := h.dataImporter.ImportFromReader(c.Context(), reader); err != nil {
+	c.Location("/api/v1/import/jobs/" + job.ID)
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "Import started",
+		"data":    job,
+	})
+}
+
+// GetImportJob reports the progress of a background import
+func (h *ImportHandler) GetImportJob(c *fiber.Ctx) error {
+	job, err := h.dataImporter.GetImportJob(c.Context(), c.Params("id"))
+	if errors.Is(err, service.ErrImportJobNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
 	return c.JSON(fiber.Map{
-		"message": "Import completed successfully",
+		"data": job,
 	})
 }
 
@@ -78,7 +111,13 @@ func (h *ImportHandler) ImportLanguages(c *fiber.Ctx) error {
 	reader := bufio.NewReader(uploadedFile)
 
 	// Start the import process
-	if err := h.dataImporter.ImportLanguages(c.Context(), reader); err != nil {
+	err = h.dataImporter.ImportLanguages(c.Context(), reader)
+	if errors.Is(err, service.ErrInvalidLanguage) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -89,14 +128,90 @@ func (h *ImportHandler) ImportLanguages(c *fiber.Ctx) error {
 	})
 }
 
-func (h *ImportHandler) ClearDatabase(c *fiber.Ctx) error {
-	if err := h.dataImporter.ClearDatabase(c.Context()); err != nil {
+// ImportConcepts stores concept metadata from a JSON array or JSON lines
+// file, optionally gzip or zip compressed
+func (h *ImportHandler) ImportConcepts(c *fiber.Ctx) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "No file uploaded: " + err.Error(),
+		})
+	}
+
+	uploadedFile, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to open uploaded file: " + err.Error(),
+		})
+	}
+	defer uploadedFile.Close()
+
+	imported, err := h.dataImporter.ImportConcepts(c.Context(), uploadedFile)
+	if errors.Is(err, service.ErrInvalidConcept) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
 	return c.JSON(fiber.Map{
-		"message": "Database cleared successfully",
+		"message":  "Concepts imported successfully",
+		"imported": imported,
+	})
+}
+
+// ClearDatabase deletes the service's data in two steps. Without a confirm
+// token it only issues one for the requested scope; repeating the request
+// with that token performs the delete.
+func (h *ImportHandler) ClearDatabase(c *fiber.Ctx) error {
+	scope, err := service.ParseClearScope(c.Query("scope"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	token := c.Query("confirm")
+	if token == "" {
+		token, err := h.dataImporter.PrepareClear(c.Context(), scope)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error":   "confirmation required, repeat the request with the confirm token within 5 minutes",
+			"scope":   scope,
+			"confirm": token,
+		})
+	}
+
+	deleted, err := h.dataImporter.ClearDatabase(c.Context(), scope, token)
+	if errors.Is(err, service.ErrInvalidConfirmation) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if errors.Is(err, service.ErrImportInProgress) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":        err.Error(),
+			"deleted_keys": deleted,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":      "Database cleared successfully",
+		"scope":        scope,
+		"deleted_keys": deleted,
 	})
 }
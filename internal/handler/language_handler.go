@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"cognet-world-inquiry-service/internal/model"
+	"cognet-world-inquiry-service/internal/service"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+type LanguageHandler struct {
+	languageCatalog service.LanguageCatalog
+}
+
+func NewLanguageHandler(languageCatalog service.LanguageCatalog) *LanguageHandler {
+	return &LanguageHandler{
+		languageCatalog: languageCatalog,
+	}
+}
+
+// ListLanguages returns every language, ordered by code
+func (h *LanguageHandler) ListLanguages(c *fiber.Ctx) error {
+	languages, err := h.languageCatalog.ListLanguages(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": languages,
+	})
+}
+
+// GetLanguage returns one language by its code
+func (h *LanguageHandler) GetLanguage(c *fiber.Ctx) error {
+	info, err := h.languageCatalog.GetLanguage(c.Context(), strings.ToLower(c.Params("code")))
+	if errors.Is(err, service.ErrLanguageNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": info,
+	})
+}
+
+// PutLanguage creates or replaces one language. The code in the body is
+// optional but has to match the path.
+func (h *LanguageHandler) PutLanguage(c *fiber.Ctx) error {
+	// The code is stored, so it must not share the request buffer
+	code := strings.ToLower(utils.CopyString(c.Params("code")))
+
+	var info model.LanguageInfo
+	if err := c.BodyParser(&info); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("failed to parse language: %v", err),
+		})
+	}
+	if info.Code != "" && strings.ToLower(info.Code) != code {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("code %q in the body does not match %q", info.Code, code),
+		})
+	}
+	info.Code = code
+
+	created, err := h.languageCatalog.PutLanguage(c.Context(), info)
+	if errors.Is(err, service.ErrInvalidLanguage) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	status := fiber.StatusOK
+	if created {
+		status = fiber.StatusCreated
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"data": info,
+	})
+}
+
+// DeleteLanguage removes one language
+func (h *LanguageHandler) DeleteLanguage(c *fiber.Ctx) error {
+	err := h.languageCatalog.DeleteLanguage(c.Context(), strings.ToLower(c.Params("code")))
+	if errors.Is(err, service.ErrLanguageNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetMissingLanguages lists language codes found in the cognate data that
+// have no language metadata
+func (h *LanguageHandler) GetMissingLanguages(c *fiber.Ctx) error {
+	codes, err := h.languageCatalog.MissingLanguages(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": codes,
+	})
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// queryList collects a multi-valued query parameter. Both repeated keys
+// (?lang=tur&lang=eng) and comma-separated values (?lang=tur,eng) are accepted.
+func queryList(c *fiber.Ctx, key string) []string {
+	var values []string
+	for _, raw := range c.Context().QueryArgs().PeekMulti(key) {
+		for _, value := range strings.Split(string(raw), ",") {
+			value = strings.ToLower(strings.TrimSpace(value))
+			if value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
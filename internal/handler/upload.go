@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"io"
+	"mime/multipart"
+	"os"
+)
+
+// spooledFile is a temporary copy of an upload that removes itself on Close
+type spooledFile struct {
+	*os.File
+}
+
+func (f *spooledFile) Close() error {
+	err := f.File.Close()
+	os.Remove(f.Name())
+	return err
+}
+
+// spoolUpload copies a multipart upload to a temporary file so it can be
+// read after the request has finished
+func spoolUpload(header *multipart.FileHeader) (io.ReadCloser, error) {
+	uploaded, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer uploaded.Close()
+
+	tmp, err := os.CreateTemp("", "cognet-import-*")
+	if err != nil {
+		return nil, err
+	}
+	spooled := &spooledFile{File: tmp}
+
+	if _, err := io.Copy(tmp, uploaded); err != nil {
+		spooled.Close()
+		return nil, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		spooled.Close()
+		return nil, err
+	}
+
+	return spooled, nil
+}
@@ -9,3 +9,14 @@ type Cognate struct {
 	Translit1 string `json:"translit1,omitempty"`
 	Translit2 string `json:"translit2,omitempty"`
 }
+
+// PairKey identifies a cognate pair within its concept regardless of which
+// side each word was listed on
+func (c Cognate) PairKey() string {
+	a := c.Lang1 + ":" + c.Word1
+	b := c.Lang2 + ":" + c.Word2
+	if b < a {
+		a, b = b, a
+	}
+	return a + "|" + b
+}
@@ -0,0 +1,10 @@
+package model
+
+// ConceptInfo describes the WordNet synset behind a concept ID
+type ConceptInfo struct {
+	ConceptID string   `json:"concept_id"`
+	POS       string   `json:"pos"` // WordNet part of speech: n, v, a, s or r
+	Lemmas    []string `json:"lemmas,omitempty"`
+	Gloss     string   `json:"gloss,omitempty"`
+	Examples  []string `json:"examples,omitempty"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+const (
+	DatasetVersionBuilding = "building"
+	DatasetVersionReady    = "ready"
+	DatasetVersionFailed   = "failed"
+)
+
+type DatasetVersion struct {
+	Version     int64      `json:"version"`
+	Status      string     `json:"status"`
+	Mode        string     `json:"mode"`
+	JobID       string     `json:"job_id,omitempty"`
+	BaseVersion int64      `json:"base_version,omitempty"` // version the data was copied from
+	Records     int64      `json:"records"`
+	Active      bool       `json:"active"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
@@ -0,0 +1,33 @@
+package model
+
+// BoundingBox is the smallest box holding a set of coordinates in degrees.
+// West is greater than East when the box crosses the antimeridian.
+type BoundingBox struct {
+	South float64 `json:"south"`
+	West  float64 `json:"west"`
+	North float64 `json:"north"`
+	East  float64 `json:"east"`
+}
+
+// GeoStats describes the geographic spread of a set of cognates, computed
+// over the coordinates of their languages. The location fields are left out
+// when no language has coordinates.
+type GeoStats struct {
+	Languages        int          `json:"languages"`
+	LocatedLanguages int          `json:"located_languages"`
+	Countries        int          `json:"countries"`
+	Centroid         []float64    `json:"centroid,omitempty"` // [lat, long]
+	BoundingBox      *BoundingBox `json:"bbox,omitempty"`
+	// MaxDistanceKm is the largest great-circle distance between two of the
+	// languages, FarthestLanguages their codes
+	MaxDistanceKm     float64  `json:"max_distance_km"`
+	FarthestLanguages []string `json:"farthest_languages,omitempty"`
+}
+
+// ConceptGeoResponse holds the geographic spread of all cognates of a concept
+type ConceptGeoResponse struct {
+	ConceptID        string       `json:"concept_id"`
+	Concept          *ConceptInfo `json:"concept,omitempty"`
+	Stats            GeoStats     `json:"stats"`
+	MissingLanguages []string     `json:"-"` // sent beside data, see DataResponse
+}
@@ -0,0 +1,22 @@
+package model
+
+// GeoJSON (RFC 7946) types. Positions are [long, lat], the reverse of
+// LanguageInfo.Coordinates.
+
+type FeatureCollection struct {
+	Type     string    `json:"type"` // always "FeatureCollection"
+	Features []Feature `json:"features"`
+}
+
+type Feature struct {
+	Type       string                 `json:"type"` // always "Feature"
+	ID         string                 `json:"id,omitempty"`
+	Geometry   Geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Geometry is a Point with one position or a LineString with several
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
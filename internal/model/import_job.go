@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+const (
+	ImportJobQueued      = "queued"
+	ImportJobRunning     = "running"
+	ImportJobCompleted   = "completed"
+	ImportJobFailed      = "failed"
+	ImportJobInterrupted = "interrupted" // the process running the job went away
+)
+
+type ImportJob struct {
+	ID             string     `json:"id"`
+	Status         string     `json:"status"`
+	Mode           string     `json:"mode"`
+	DryRun         bool       `json:"dry_run,omitempty"` // validate only, nothing is written
+	Version        int64      `json:"version,omitempty"` // dataset version written to
+	RowsRead       int64      `json:"rows_read"`
+	RowsWritten    int64      `json:"rows_written"`
+	RowsSkipped    int64      `json:"rows_skipped"`   // rejected by validation
+	RowsDuplicate  int64      `json:"rows_duplicate"` // pairs already stored or repeated in the file
+	BytesProcessed int64      `json:"bytes_processed"`
+	TotalBytes     int64      `json:"total_bytes,omitempty"`
+	RowsPerSecond  float64    `json:"rows_per_second"`
+	ETASeconds     *int64     `json:"eta_seconds,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	Error          string     `json:"error,omitempty"`
+
+	Report ValidationReport `json:"report"`
+}
+
+// Reasons a row is rejected
+const (
+	RejectMalformedRow       = "malformed_row" // a CSV or JSON line that does not parse
+	RejectTooFewColumns      = "too_few_columns"
+	RejectEmptyWord          = "empty_word"
+	RejectInvalidLanguage    = "invalid_language"
+	RejectMalformedConceptID = "malformed_concept_id"
+	RejectDuplicate          = "duplicate"
+)
+
+// ValidationReport groups the rejected rows of an import by reason
+type ValidationReport struct {
+	Rejected map[string]*RejectedRows `json:"rejected"`
+}
+
+// RejectedRows counts the rows rejected for one reason. Lines holds the
+// first line numbers, counting the header as line 1.
+type RejectedRows struct {
+	Count int64   `json:"count"`
+	Lines []int64 `json:"lines"`
+}
@@ -5,5 +5,6 @@ type LanguageInfo struct {
 	Name        string    `json:"name"`        // e.g., "English", "Turkish"
 	Coordinates []float64 `json:"coordinates"` // [lat, long]
 	Flag        string    `json:"flag"`        // URL to flag image
-	Country     string    `json:country`
+	Country     string    `json:"country"`
+	Unknown     bool      `json:"unknown,omitempty"` // no metadata, only Code is set
 }
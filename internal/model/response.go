@@ -10,19 +10,121 @@ type WordSuggestionResponse struct {
 	Word         string       `json:"word"`
 	ConceptID    string       `json:"concept_id"`
 	LanguageInfo LanguageInfo `json:"language_info"`
+	Concept      *ConceptInfo `json:"concept,omitempty"` // nil when no metadata was imported
 }
 
+// DataResponse is the envelope of results that are a single object. Every
+// search response holds its result in data, with missing_languages and
+// paging fields next to it.
+type DataResponse struct {
+	Data             interface{} `json:"data"`
+	MissingLanguages []string    `json:"missing_languages,omitempty"`
+}
+
+// SuggestionPage is one page of ranked prefix suggestions. NextCursor is zero
+// once there are no more results.
+type SuggestionPage struct {
+	Suggestions      []WordSuggestionResponse `json:"data"`
+	NextCursor       int64                    `json:"next_cursor"`
+	MissingLanguages []string                 `json:"missing_languages,omitempty"`
+}
+
+// WordLookupResponse lists every concept an exact word takes part in
+type WordLookupResponse struct {
+	Results          []WordSuggestionResponse `json:"data"`
+	MissingLanguages []string                 `json:"missing_languages,omitempty"`
+}
+
+// ConceptResponse holds the cognate pairs of a concept and its metadata
+type ConceptResponse struct {
+	Cognates []Cognate    `json:"data"`
+	Concept  *ConceptInfo `json:"concept,omitempty"`
+}
+
+// ConceptSearchResult is a concept matching a text query, higher scores
+// match better
+type ConceptSearchResult struct {
+	Concept ConceptInfo `json:"concept"`
+	Score   float64     `json:"score"`
+}
+
+// ConceptSearchPage is one page of ranked concepts. NextCursor is zero once
+// there are no more results.
+type ConceptSearchPage struct {
+	Results    []ConceptSearchResult `json:"data"`
+	Total      int                   `json:"total"`
+	NextCursor int64                 `json:"next_cursor"`
+}
+
+// ChainWord is a node of a cognate chain, ID is "<lang>:<word>". The
+// coordinates of LanguageInfo are the word's map marker position, moved off
+// other words of the same response; OriginalCoordinates are the language's.
 type ChainWord struct {
-	Word         string       `json:"word"`
-	Translit1    string       `json:"translit1"`
-	LanguageInfo LanguageInfo `json:"language_info"`
+	ID                  string       `json:"id"`
+	Word                string       `json:"word"`
+	Translit1           string       `json:"translit1"`
+	LanguageInfo        LanguageInfo `json:"language_info"`
+	OriginalCoordinates []float64    `json:"original_coordinates,omitempty"`
+}
+
+// ChainEdge is a cognate pair linking two chain words by their IDs
+type ChainEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
 }
 
+// CognateChain is a connected group of cognates. Nodes start at the smallest
+// word and follow the edges breadth-first, edges are ordered by the position
+// of their words.
 type CognateChain struct {
-	Chain []ChainWord `json:"chain"`
+	Nodes []ChainWord `json:"nodes"`
+	Edges []ChainEdge `json:"edges"`
+	Stats *GeoStats   `json:"stats,omitempty"`
+}
+
+// CognatePathResponse is a shortest chain of cognates between two words of
+// a concept. Path and Edges are empty when the words are not connected,
+// Edges[i] links Path[i] and Path[i+1].
+type CognatePathResponse struct {
+	ConceptID        string       `json:"concept_id"`
+	Concept          *ConceptInfo `json:"concept,omitempty"`
+	Connected        bool         `json:"connected"`
+	Path             []ChainWord  `json:"path"`
+	Edges            []Cognate    `json:"edges"`
+	MissingLanguages []string     `json:"-"` // sent beside data, see DataResponse
+}
+
+// WordNeighbor is a cognate of a word, Distance is the number of cognate
+// pairs between them across all concepts of the word
+type WordNeighbor struct {
+	ChainWord
+	Distance int `json:"distance"`
+}
+
+// WordGraphConcept lists the cognates of a word within one of its concepts.
+// LanguageCounts is the number of cognates per language code.
+type WordGraphConcept struct {
+	ConceptID      string         `json:"concept_id"`
+	Concept        *ConceptInfo   `json:"concept,omitempty"`
+	Neighbors      []WordNeighbor `json:"neighbors"`
+	LanguageCounts map[string]int `json:"language_counts"`
+}
+
+// WordGraphResponse gathers the cognates of a word across every concept it
+// takes part in. LanguageCounts counts each distinct cognate once, however
+// many concepts share it.
+type WordGraphResponse struct {
+	Word             string             `json:"word"`
+	LanguageInfo     LanguageInfo       `json:"language_info"`
+	Concepts         []WordGraphConcept `json:"concepts"`
+	LanguageCounts   map[string]int     `json:"language_counts"`
+	MissingLanguages []string           `json:"-"` // sent beside data, see DataResponse
 }
 
 type CognateChainResponse struct {
-	ConceptID string         `json:"concept_id"`
-	Chains    []CognateChain `json:"chains"`
+	ConceptID        string         `json:"concept_id"`
+	Concept          *ConceptInfo   `json:"concept,omitempty"`
+	Chains           []CognateChain `json:"chains"`
+	Stats            *GeoStats      `json:"stats,omitempty"` // over every chain returned
+	MissingLanguages []string       `json:"-"`               // sent beside data, see DataResponse
 }
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"cognet-world-inquiry-service/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// ClearScope selects which part of the service's data is deleted
+type ClearScope string
+
+const (
+	ClearScopeAll       ClearScope = "all"
+	ClearScopeCognates  ClearScope = "cognates"
+	ClearScopeLanguages ClearScope = "languages"
+	ClearScopeConcepts  ClearScope = "concepts"
+)
+
+var ErrInvalidConfirmation = errors.New("missing or invalid confirmation token")
+
+// clearTokenTTL is how long a confirmation token can be used
+const clearTokenTTL = 5 * time.Minute
+
+// ParseClearScope validates a scope name, an empty name means all. Like
+// ParseImportMode it never returns name itself, which may be a reused
+// request buffer.
+func ParseClearScope(name string) (ClearScope, error) {
+	switch ClearScope(name) {
+	case "", ClearScopeAll:
+		return ClearScopeAll, nil
+	case ClearScopeCognates:
+		return ClearScopeCognates, nil
+	case ClearScopeLanguages:
+		return ClearScopeLanguages, nil
+	case ClearScopeConcepts:
+		return ClearScopeConcepts, nil
+	default:
+		return "", fmt.Errorf("invalid clear scope %q, expected all, cognates, languages or concepts", name)
+	}
+}
+
+// PrepareClear issues a single use token that has to be passed to
+// ClearDatabase to delete the given scope
+func (d *dataImporter) PrepareClear(ctx context.Context, scope ClearScope) (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	if err := d.store.SaveClearToken(ctx, token, string(scope), clearTokenTTL); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ClearDatabase deletes this service's data for the scope, leaving anything
+// else stored alongside it untouched. It returns the number of deleted
+// entries. Clearing cognates holds the import lease, so it fails with
+// ErrImportInProgress while an import writes a version, and no import starts
+// until it is done; the token can be used again once the import finished.
+func (d *dataImporter) ClearDatabase(ctx context.Context, scope ClearScope, token string) (int64, error) {
+	if token == "" {
+		return 0, ErrInvalidConfirmation
+	}
+
+	if scope == ClearScopeAll || scope == ClearScopeCognates {
+		leaseCtx, leases, err := d.acquireLeases(ctx, "clear:"+uuid.NewString(), []string{importLease})
+		if err != nil {
+			return 0, err
+		}
+		defer leases.release(context.WithoutCancel(ctx))
+		ctx = leaseCtx
+	}
+
+	confirmed, err := d.store.TakeClearToken(ctx, token)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && confirmed != string(scope)) {
+		return 0, ErrInvalidConfirmation
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	d.setStatus(fmt.Sprintf("clearing %s", scope))
+	defer d.setStatus("ready")
+
+	var total int64
+	progress := func(deleted int64) {
+		d.setStatus(fmt.Sprintf("clearing %s (%d keys deleted)", scope, total+deleted))
+	}
+
+	if scope == ClearScopeAll || scope == ClearScopeCognates {
+		deleted, err := d.store.ClearCognates(ctx, progress)
+		total += deleted
+		if err != nil {
+			return total, err
+		}
+	}
+	if scope == ClearScopeAll || scope == ClearScopeLanguages {
+		deleted, err := d.store.ClearLanguages(ctx, progress)
+		total += deleted
+		if err != nil {
+			return total, err
+		}
+
+		if err := d.languages.Invalidate(ctx); err != nil {
+			return total, fmt.Errorf("failed to refresh languages: %w", err)
+		}
+	}
+	if scope == ClearScopeAll || scope == ClearScopeConcepts {
+		deleted, err := d.store.ClearConcepts(ctx, progress)
+		total += deleted
+		if err != nil {
+			return total, err
+		}
+	}
+
+	log.Printf("cleared %s: %d keys deleted", scope, total)
+	return total, nil
+}
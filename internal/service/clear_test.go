@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cognet-world-inquiry-service/internal/model"
+	"cognet-world-inquiry-service/internal/store"
+)
+
+func TestClearDatabaseWaitsForImports(t *testing.T) {
+	ctx := context.Background()
+	cognateStore := store.NewMemoryStore()
+	importer := NewDataImporter(cognateStore, NewLanguageRegistry(cognateStore)).(*dataImporter)
+
+	if _, err := importer.writeCognates(ctx, 1, []model.Cognate{
+		{ConceptID: "n00000001", Lang1: "eng", Word1: "bank", Lang2: "deu", Word2: "Bank"},
+	}, ImportModeAppend); err != nil {
+		t.Fatal(err)
+	}
+	if acquired, err := cognateStore.AcquireLease(ctx, importLease, "job-1", time.Minute); err != nil || !acquired {
+		t.Fatalf("AcquireLease() = %v, %v", acquired, err)
+	}
+
+	tests := []struct {
+		scope   ClearScope
+		wantErr error
+	}{
+		{scope: ClearScopeAll, wantErr: ErrImportInProgress},
+		{scope: ClearScopeCognates, wantErr: ErrImportInProgress},
+		// Languages and concepts are not written by cognate imports
+		{scope: ClearScopeLanguages},
+		{scope: ClearScopeConcepts},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.scope), func(t *testing.T) {
+			token, err := importer.PrepareClear(ctx, tt.scope)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := importer.ClearDatabase(ctx, tt.scope, token); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ClearDatabase() error = %v, want %v", err, tt.wantErr)
+			}
+			if owner, _ := cognateStore.LeaseOwner(ctx, importLease); owner != "job-1" {
+				t.Errorf("import lease owner = %q, want job-1", owner)
+			}
+		})
+	}
+
+	cognates, err := cognateStore.GetConcept(ctx, 1, "n00000001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cognates) != 1 {
+		t.Fatalf("the running import lost its cognates")
+	}
+
+	// The token refused during the import still works once it finished
+	token, err := importer.PrepareClear(ctx, ClearScopeCognates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := importer.ClearDatabase(ctx, ClearScopeCognates, token); !errors.Is(err, ErrImportInProgress) {
+		t.Fatalf("ClearDatabase() error = %v, want ErrImportInProgress", err)
+	}
+	if err := cognateStore.ReleaseLease(ctx, importLease, "job-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := importer.ClearDatabase(ctx, ClearScopeCognates, token); err != nil {
+		t.Fatalf("ClearDatabase() error = %v", err)
+	}
+	if owner, _ := cognateStore.LeaseOwner(ctx, importLease); owner != "" {
+		t.Errorf("clear kept the import lease for %q", owner)
+	}
+	if cognates, _ := cognateStore.GetConcept(ctx, 1, "n00000001"); len(cognates) != 0 {
+		t.Errorf("%d cognates left after clearing", len(cognates))
+	}
+}
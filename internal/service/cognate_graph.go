@@ -0,0 +1,225 @@
+package service
+
+import (
+	"sort"
+
+	"cognet-world-inquiry-service/internal/model"
+)
+
+// graphNode is a word of a cognate graph
+type graphNode struct {
+	Lang     string
+	Word     string
+	Translit string
+}
+
+// cognateGraph links the words of cognate pairs. Nodes are keyed by
+// nodeKey and neighbours are kept sorted, so every walk over the graph
+// visits words in the same order.
+type cognateGraph struct {
+	nodes     map[string]*graphNode
+	neighbors map[string][]string
+	edges     map[[2]string]model.Cognate
+}
+
+// nodeKey identifies a word within a graph, language codes have a fixed
+// length so keys sort by language first
+func nodeKey(lang, word string) string {
+	return lang + ":" + word
+}
+
+// edgeKey is the same for both directions of a pair
+func edgeKey(a, b string) [2]string {
+	if b < a {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
+
+func newCognateGraph(cognates []model.Cognate) *cognateGraph {
+	g := &cognateGraph{
+		nodes:     make(map[string]*graphNode),
+		neighbors: make(map[string][]string),
+		edges:     make(map[[2]string]model.Cognate),
+	}
+	for _, cognate := range cognates {
+		g.add(cognate)
+	}
+	for key := range g.neighbors {
+		sort.Strings(g.neighbors[key])
+	}
+	return g
+}
+
+// add links the two words of a pair, the first pair linking two words is
+// kept as their edge
+func (g *cognateGraph) add(cognate model.Cognate) {
+	a := g.node(cognate.Lang1, cognate.Word1, cognate.Translit1)
+	b := g.node(cognate.Lang2, cognate.Word2, cognate.Translit2)
+	if a == b {
+		return
+	}
+
+	key := edgeKey(a, b)
+	if _, ok := g.edges[key]; ok {
+		return
+	}
+	g.edges[key] = cognate
+	g.neighbors[a] = append(g.neighbors[a], b)
+	g.neighbors[b] = append(g.neighbors[b], a)
+}
+
+// node returns the key of a word, adding it on first sight. The first
+// transliteration seen for a word is kept.
+func (g *cognateGraph) node(lang, word, translit string) string {
+	key := nodeKey(lang, word)
+	node, ok := g.nodes[key]
+	if !ok {
+		node = &graphNode{Lang: lang, Word: word}
+		g.nodes[key] = node
+	}
+	if node.Translit == "" {
+		node.Translit = translit
+	}
+	return key
+}
+
+// edge returns the cognate pair linking two words
+func (g *cognateGraph) edge(a, b string) (model.Cognate, bool) {
+	cognate, ok := g.edges[edgeKey(a, b)]
+	return cognate, ok
+}
+
+// sortedKeys returns every node key in order
+func (g *cognateGraph) sortedKeys() []string {
+	keys := make([]string, 0, len(g.nodes))
+	for key := range g.nodes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// component returns the words connected to start in breadth-first order
+func (g *cognateGraph) component(start string) []string {
+	visited := map[string]bool{start: true}
+	order := []string{start}
+	for i := 0; i < len(order); i++ {
+		for _, next := range g.neighbors[order[i]] {
+			if !visited[next] {
+				visited[next] = true
+				order = append(order, next)
+			}
+		}
+	}
+	return order
+}
+
+// distances returns the number of pairs between start and every word
+// connected to it
+func (g *cognateGraph) distances(start string) map[string]int {
+	distance := map[string]int{start: 0}
+	queue := []string{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range g.neighbors[current] {
+			if _, seen := distance[next]; !seen {
+				distance[next] = distance[current] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return distance
+}
+
+// components splits the graph into its connected parts. Each one starts at
+// its smallest word, larger parts come first.
+func (g *cognateGraph) components() [][]string {
+	seen := make(map[string]bool, len(g.nodes))
+	var components [][]string
+	for _, key := range g.sortedKeys() {
+		if seen[key] {
+			continue
+		}
+		component := g.component(key)
+		for _, member := range component {
+			seen[member] = true
+		}
+		components = append(components, component)
+	}
+
+	sort.SliceStable(components, func(i, j int) bool {
+		return len(components[i]) > len(components[j])
+	})
+	return components
+}
+
+// componentEdges returns the pairs between the words of a component, each
+// once, ordered by the position of their words in the component
+func (g *cognateGraph) componentEdges(component []string) [][2]string {
+	position := make(map[string]int, len(component))
+	for i, key := range component {
+		position[key] = i
+	}
+
+	var edges [][2]string
+	for _, from := range component {
+		for _, to := range g.neighbors[from] {
+			if position[to] > position[from] {
+				edges = append(edges, [2]string{from, to})
+			}
+		}
+	}
+	sort.SliceStable(edges, func(i, j int) bool {
+		if position[edges[i][0]] != position[edges[j][0]] {
+			return position[edges[i][0]] < position[edges[j][0]]
+		}
+		return position[edges[i][1]] < position[edges[j][1]]
+	})
+	return edges
+}
+
+// shortestPath returns the words of a shortest path from one word to
+// another, both included, or nil when they are not connected. Neighbours
+// are tried in order, so ties always resolve to the same path.
+func (g *cognateGraph) shortestPath(from, to string) []string {
+	if _, ok := g.nodes[from]; !ok {
+		return nil
+	}
+	if from == to {
+		return []string{from}
+	}
+
+	previous := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range g.neighbors[current] {
+			if _, seen := previous[next]; seen {
+				continue
+			}
+			previous[next] = current
+			if next == to {
+				return tracePath(previous, from, to)
+			}
+			queue = append(queue, next)
+		}
+	}
+	return nil
+}
+
+// tracePath follows the breadth-first predecessors back from to
+func tracePath(previous map[string]string, from, to string) []string {
+	var path []string
+	for key := to; key != from; key = previous[key] {
+		path = append(path, key)
+	}
+	path = append(path, from)
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cognet-world-inquiry-service/internal/model"
+)
+
+// chainCognates form a triangle with a tail and, apart from it, one pair
+// stored in both directions
+var chainCognates = []model.Cognate{
+	{ConceptID: "n00000001", Lang1: "eng", Word1: "bank", Lang2: "deu", Word2: "bank"},
+	{ConceptID: "n00000001", Lang1: "deu", Word1: "bank", Lang2: "fra", Word2: "banque"},
+	{ConceptID: "n00000001", Lang1: "eng", Word1: "bank", Lang2: "fra", Word2: "banque"},
+	{ConceptID: "n00000001", Lang1: "fra", Word1: "banque", Lang2: "ita", Word2: "banca"},
+	{ConceptID: "n00000001", Lang1: "tur", Word1: "balık", Lang2: "aze", Word2: "balıq"},
+	{ConceptID: "n00000001", Lang1: "aze", Word1: "balıq", Lang2: "tur", Word2: "balık"},
+}
+
+// describeChains lists every chain as its nodes, then its edges
+func describeChains(chains []model.CognateChain) []string {
+	described := make([]string, 0, len(chains))
+	for _, chain := range chains {
+		parts := make([]string, 0, len(chain.Nodes)+len(chain.Edges)+1)
+		for _, node := range chain.Nodes {
+			parts = append(parts, node.ID)
+		}
+		parts = append(parts, "|")
+		for _, edge := range chain.Edges {
+			parts = append(parts, edge.From+"-"+edge.To)
+		}
+		described = append(described, strings.Join(parts, " "))
+	}
+	return described
+}
+
+func TestFindCognateChains(t *testing.T) {
+	large := "deu:bank eng:bank fra:banque ita:banca | deu:bank-eng:bank deu:bank-fra:banque eng:bank-fra:banque fra:banque-ita:banca"
+	small := "aze:balıq tur:balık | aze:balıq-tur:balık"
+
+	tests := []struct {
+		name    string
+		opts    ChainOptions
+		want    []string
+		missing []string
+	}{
+		{name: "all chains, largest first", want: []string{large, small}, missing: []string{"aze", "deu", "eng", "fra", "ita", "tur"}},
+		{name: "chain of a word", opts: ChainOptions{Word: "balık", Lang: "tur"}, want: []string{small}, missing: []string{"aze", "tur"}},
+		{name: "chain of a word at its end", opts: ChainOptions{Word: "banca", Lang: "ita"}, want: []string{large}, missing: []string{"deu", "eng", "fra", "ita"}},
+		{name: "word in another language", opts: ChainOptions{Word: "bank", Lang: "fra"}, want: []string{}},
+		{name: "word without language", opts: ChainOptions{Word: "balık"}, want: []string{large, small}, missing: []string{"aze", "deu", "eng", "fra", "ita", "tur"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The storage order of the pairs does not matter
+			for seed := int64(0); seed < 3; seed++ {
+				shuffled := append([]model.Cognate(nil), chainCognates...)
+				rand.New(rand.NewSource(seed)).Shuffle(len(shuffled), func(i, j int) {
+					shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+				})
+				search, _ := newTestSearch(t, shuffled)
+
+				response, err := search.FindCognateChains(context.Background(), "n00000001", tt.opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := describeChains(response.Chains); !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("seed %d: chains =\n%s\nwant\n%s", seed, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+				}
+				if !reflect.DeepEqual(response.MissingLanguages, tt.missing) {
+					t.Errorf("missing languages = %v, want %v", response.MissingLanguages, tt.missing)
+				}
+			}
+		})
+	}
+}
+
+func TestFindCognateChainsUnknownConcept(t *testing.T) {
+	search, _ := newTestSearch(t, chainCognates)
+
+	response, err := search.FindCognateChains(context.Background(), "n99999999", ChainOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Chains) != 0 || response.Chains == nil {
+		t.Errorf("chains = %#v, want an empty list", response.Chains)
+	}
+}
+
+func TestCognateGraphIgnoresSelfPairs(t *testing.T) {
+	graph := newCognateGraph([]model.Cognate{
+		{ConceptID: "n00000001", Lang1: "eng", Word1: "bank", Lang2: "eng", Word2: "bank"},
+		{ConceptID: "n00000001", Lang1: "eng", Word1: "bank", Lang2: "deu", Word2: "bank"},
+	})
+
+	if got := describeChainKeys(graph.components()); !reflect.DeepEqual(got, []string{"deu:bank eng:bank"}) {
+		t.Errorf("components = %v", got)
+	}
+	if edges := graph.componentEdges(graph.components()[0]); len(edges) != 1 {
+		t.Errorf("edges = %v, want the one pair between different words", edges)
+	}
+}
+
+// describeChainKeys lists the words of every component
+func describeChainKeys(components [][]string) []string {
+	described := make([]string, 0, len(components))
+	for _, component := range components {
+		described = append(described, strings.Join(component, " "))
+	}
+	return described
+}
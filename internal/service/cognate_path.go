@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cognet-world-inquiry-service/internal/model"
+)
+
+var ErrWordNotInConcept = errors.New("word not found in concept")
+
+// FindPath returns a shortest chain of cognate pairs leading from one word of
+// a concept to another. Words that are in the concept but not linked give a
+// response with Connected false.
+func (cs *cognateSearch) FindPath(ctx context.Context, conceptID, fromLang, fromWord, toLang, toWord string) (*model.CognatePathResponse, error) {
+	cognates, err := cs.loadConcept(ctx, conceptID)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := newCognateGraph(cognates)
+	from, to := nodeKey(fromLang, fromWord), nodeKey(toLang, toWord)
+	for _, key := range []string{from, to} {
+		if _, ok := graph.nodes[key]; !ok {
+			return nil, fmt.Errorf("%w: %s in %s", ErrWordNotInConcept, key, conceptID)
+		}
+	}
+
+	response := &model.CognatePathResponse{
+		ConceptID: conceptID,
+		Path:      []model.ChainWord{},
+		Edges:     []model.Cognate{},
+	}
+
+	path := graph.shortestPath(from, to)
+	if path != nil {
+		response.Connected = true
+		resolver := cs.newLanguageResolver()
+		positions := cs.markerPositions(graph, resolver)
+		for i, key := range path {
+			response.Path = append(response.Path, cs.chainWord(graph, key, resolver, positions))
+			if i > 0 {
+				cognate, _ := graph.edge(path[i-1], key)
+				response.Edges = append(response.Edges, cognate)
+			}
+		}
+		response.MissingLanguages = resolver.missingLanguages()
+	}
+
+	concept, err := cs.conceptInfo(ctx, conceptID)
+	if err != nil {
+		return nil, err
+	}
+	response.Concept = concept
+
+	return response, nil
+}
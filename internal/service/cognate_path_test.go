@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"cognet-world-inquiry-service/internal/model"
+)
+
+func TestFindPath(t *testing.T) {
+	// Two ways of the same length lead from eng:w to ita:z
+	square := []model.Cognate{
+		{ConceptID: "n00000002", Lang1: "eng", Word1: "w", Lang2: "fra", Word2: "y"},
+		{ConceptID: "n00000002", Lang1: "eng", Word1: "w", Lang2: "deu", Word2: "x"},
+		{ConceptID: "n00000002", Lang1: "ita", Word1: "z", Lang2: "fra", Word2: "y"},
+		{ConceptID: "n00000002", Lang1: "ita", Word1: "z", Lang2: "deu", Word2: "x"},
+	}
+	search, _ := newTestSearch(t, append(append([]model.Cognate(nil), chainCognates...), square...))
+
+	tests := []struct {
+		name      string
+		conceptID string
+		from, to  [2]string
+		path      []string
+		edges     []model.Cognate
+	}{
+		{
+			name:      "shortest of two ways",
+			conceptID: "n00000001",
+			from:      [2]string{"eng", "bank"}, to: [2]string{"ita", "banca"},
+			path:  []string{"eng:bank", "fra:banque", "ita:banca"},
+			edges: []model.Cognate{chainCognates[2], chainCognates[3]},
+		},
+		{
+			name:      "ties take the smallest word",
+			conceptID: "n00000002",
+			from:      [2]string{"eng", "w"}, to: [2]string{"ita", "z"},
+			path:  []string{"eng:w", "deu:x", "ita:z"},
+			edges: []model.Cognate{square[1], square[3]},
+		},
+		{
+			name:      "direct pair",
+			conceptID: "n00000001",
+			from:      [2]string{"tur", "balık"}, to: [2]string{"aze", "balıq"},
+			path:  []string{"tur:balık", "aze:balıq"},
+			edges: []model.Cognate{chainCognates[4]},
+		},
+		{
+			name:      "same word",
+			conceptID: "n00000001",
+			from:      [2]string{"eng", "bank"}, to: [2]string{"eng", "bank"},
+			path:  []string{"eng:bank"},
+			edges: []model.Cognate{},
+		},
+		{
+			name:      "not connected",
+			conceptID: "n00000001",
+			from:      [2]string{"eng", "bank"}, to: [2]string{"tur", "balık"},
+			path:  []string{},
+			edges: []model.Cognate{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := search.FindPath(context.Background(), tt.conceptID, tt.from[0], tt.from[1], tt.to[0], tt.to[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			path := make([]string, 0, len(response.Path))
+			for _, word := range response.Path {
+				path = append(path, word.ID)
+			}
+			if !reflect.DeepEqual(path, tt.path) {
+				t.Errorf("path = %v, want %v", path, tt.path)
+			}
+			if !reflect.DeepEqual(response.Edges, tt.edges) {
+				t.Errorf("edges = %+v, want %+v", response.Edges, tt.edges)
+			}
+			if response.Connected != (len(tt.path) > 0) {
+				t.Errorf("connected = %v for path %v", response.Connected, tt.path)
+			}
+		})
+	}
+}
+
+func TestFindPathWordNotInConcept(t *testing.T) {
+	search, _ := newTestSearch(t, chainCognates)
+
+	tests := []struct {
+		name      string
+		conceptID string
+		fromLang  string
+		fromWord  string
+		toLang    string
+		toWord    string
+	}{
+		{name: "unknown from word", conceptID: "n00000001", fromLang: "eng", fromWord: "shore", toLang: "ita", toWord: "banca"},
+		{name: "unknown to word", conceptID: "n00000001", fromLang: "eng", fromWord: "bank", toLang: "ita", toWord: "riva"},
+		{name: "word in another language", conceptID: "n00000001", fromLang: "eng", fromWord: "bank", toLang: "ita", toWord: "bank"},
+		{name: "unknown concept", conceptID: "n99999999", fromLang: "eng", fromWord: "bank", toLang: "ita", toWord: "banca"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := search.FindPath(context.Background(), tt.conceptID, tt.fromLang, tt.fromWord, tt.toLang, tt.toWord)
+			if !errors.Is(err, ErrWordNotInConcept) {
+				t.Errorf("FindPath() error = %v, want ErrWordNotInConcept", err)
+			}
+		})
+	}
+}
@@ -2,18 +2,22 @@ package service
 
 import (
 	"cognet-world-inquiry-service/internal/model"
+	"cognet-world-inquiry-service/internal/store"
 	"context"
-	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
-
-	"github.com/redis/go-redis/v9"
 )
 
 type CognateSearch interface {
-	GetWordSuggestions(ctx context.Context, prefix string) ([]model.WordSuggestionResponse, error)
-	FindCognateChains(ctx context.Context, conceptID, word, lang string) (*model.CognateChainResponse, error)
-	FindByConceptID(ctx context.Context, conceptID string) ([]model.Cognate, error)
+	GetWordSuggestions(ctx context.Context, prefix string, opts SuggestionOptions) (*model.SuggestionPage, error)
+	FindCognateChains(ctx context.Context, conceptID string, opts ChainOptions) (*model.CognateChainResponse, error)
+	FindByConceptID(ctx context.Context, conceptID string) (*model.ConceptResponse, error)
+	FindByWord(ctx context.Context, word, lang string) (*model.WordLookupResponse, error)
+	SearchConcepts(ctx context.Context, query string, opts ConceptSearchOptions) (*model.ConceptSearchPage, error)
+	FindWordGraph(ctx context.Context, word, lang string, depth int) (*model.WordGraphResponse, error)
+	FindConceptGeo(ctx context.Context, conceptID string) (*model.ConceptGeoResponse, error)
+	FindPath(ctx context.Context, conceptID, fromLang, fromWord, toLang, toWord string) (*model.CognatePathResponse, error)
 }
 
 type WordSuggestion struct {
@@ -22,296 +26,522 @@ type WordSuggestion struct {
 	ConceptID string `json:"concept_id"` // Added ConceptID to suggestion
 }
 
+// SuggestionOptions controls paging through the ranked prefix index
+type SuggestionOptions struct {
+	Limit        int      // page size, DefaultSuggestionLimit when zero
+	Cursor       int64    // position to resume from, 0 for the first page
+	Langs        []string // only return words in these languages
+	ExcludeLangs []string // never return words in these languages
+}
+
+// ChainOptions selects the chains of a concept and what they include
+type ChainOptions struct {
+	Word  string // with Lang, only return the chain holding this word
+	Lang  string
+	Stats bool // add the geographic spread of each chain and of all of them
+}
+
+const (
+	DefaultSuggestionLimit = 10
+	MaxSuggestionLimit     = 100
+)
+
 type cognateSearch struct {
-	redisClient *redis.Client
+	store     store.CognateStore
+	languages *LanguageRegistry
 }
 
-func NewCognateSearch(redisClient *redis.Client) CognateSearch {
+func NewCognateSearch(cognateStore store.CognateStore, languages *LanguageRegistry) CognateSearch {
 	return &cognateSearch{
-		redisClient: redisClient,
+		store:     cognateStore,
+		languages: languages,
 	}
 }
 
-type coordinateTracker struct {
-	used map[string]int // key: "lat,lng", value: count
+// languageResolver resolves the languages of one response. Every code is
+// looked up in the registry once, so a reload of the registry during the
+// request cannot give one response two versions of a language. Codes without
+// metadata get a placeholder and are remembered so the response can list them.
+type languageResolver struct {
+	languages *LanguageRegistry
+	resolved  map[string]model.LanguageInfo
+	known     map[string]bool
+	missing   map[string]bool
 }
 
-func newCoordinateTracker() *coordinateTracker {
-	return &coordinateTracker{
-		used: make(map[string]int),
+func (cs *cognateSearch) newLanguageResolver() *languageResolver {
+	return &languageResolver{
+		languages: cs.languages,
+		resolved:  make(map[string]model.LanguageInfo),
+		known:     make(map[string]bool),
+		missing:   make(map[string]bool),
 	}
 }
 
-func (ct *coordinateTracker) getAdjustedCoordinates(original []float64) []float64 {
-	key := fmt.Sprintf("%f,%f", original[0], original[1])
-	count := ct.used[key]
-	ct.used[key]++
-
-	// If this is the first word at these coordinates, return original
-	if count == 0 {
-		return original
+// lookup returns the metadata of a language as first seen by this response,
+// without counting it as missing
+func (lr *languageResolver) lookup(langCode string) (model.LanguageInfo, bool) {
+	if langInfo, ok := lr.resolved[langCode]; ok {
+		return langInfo, lr.known[langCode]
 	}
+	langInfo, ok := lr.languages.Lookup(langCode)
+	lr.resolved[langCode] = langInfo
+	lr.known[langCode] = ok
+	return langInfo, ok
+}
 
-	// Define offset patterns (in degrees)
-	// These patterns form a circular pattern around the original point
-	offsets := [][2]float64{
-		{1, 0},       // right
-		{-1, 0},      // left
-		{0, 1},       // up
-		{0, -1},      // down
-		{0.5, 0.5},   // up-right
-		{-0.5, 0.5},  // up-left
-		{0.5, -0.5},  // down-right
-		{-0.5, -0.5}, // down-left
+func (lr *languageResolver) getLanguageInfo(langCode string) model.LanguageInfo {
+	langInfo, ok := lr.lookup(langCode)
+	if !ok {
+		lr.missing[langCode] = true
+		return model.LanguageInfo{Code: langCode, Unknown: true}
 	}
+	return langInfo
+}
 
-	// Get the offset pattern based on count
-	offsetIndex := (count - 1) % len(offsets)
-	offset := offsets[offsetIndex]
+// missingLanguages returns the sorted codes that had no metadata
+func (lr *languageResolver) missingLanguages() []string {
+	return sortedCodes(lr.missing)
+}
 
-	return []float64{
-		original[0] + offset[0],
-		original[1] + offset[1],
+// chainMissingLanguages lists the codes of chain words without metadata,
+// only the chains returned are considered
+func chainMissingLanguages(chains []model.CognateChain) []string {
+	missing := make(map[string]bool)
+	for _, chain := range chains {
+		for _, chainWord := range chain.Nodes {
+			if chainWord.LanguageInfo.Unknown {
+				missing[chainWord.LanguageInfo.Code] = true
+			}
+		}
 	}
+	return sortedCodes(missing)
 }
 
-func (cs *cognateSearch) getLanguageInfo(ctx context.Context, langCode string) (model.LanguageInfo, error) {
-	data, err := cs.redisClient.Get(ctx, fmt.Sprintf("lang:%s", langCode)).Result()
-	if err != nil {
-		return model.LanguageInfo{}, fmt.Errorf("failed to get language info: %w", err)
+func sortedCodes(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
 	}
 
-	var langInfo model.LanguageInfo
-	if err := json.Unmarshal([]byte(data), &langInfo); err != nil {
-		return model.LanguageInfo{}, fmt.Errorf("failed to unmarshal language info: %w", err)
+	codes := make([]string, 0, len(set))
+	for code := range set {
+		codes = append(codes, code)
 	}
-
-	return langInfo, nil
+	sort.Strings(codes)
+	return codes
 }
 
-func (cs *cognateSearch) GetWordSuggestions(ctx context.Context, prefix string) ([]model.WordSuggestionResponse, error) {
-	if len(prefix) < 2 {
-		return []model.WordSuggestionResponse{}, nil
+func (cs *cognateSearch) GetWordSuggestions(ctx context.Context, prefix string, opts SuggestionOptions) (*model.SuggestionPage, error) {
+	page := &model.SuggestionPage{Suggestions: []model.WordSuggestionResponse{}}
+	if len([]rune(prefix)) < 2 {
+		return page, nil
 	}
 
 	prefix = strings.ToLower(prefix)
-	limit := 10
 
-	// Get matches from prefix index
-	matches, err := cs.redisClient.SMembers(ctx, fmt.Sprintf("prefix:%s", prefix)).Result()
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultSuggestionLimit
+	}
+	if limit > MaxSuggestionLimit {
+		limit = MaxSuggestionLimit
+	}
+	cursor := opts.Cursor
+	if cursor < 0 {
+		cursor = 0
+	}
+
+	version, err := cs.store.ActiveVersion(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch suggestions: %w", err)
+		return nil, err
 	}
 
-	// Process matches and remove duplicates
-	seen := make(map[string]bool)
-	suggestions := make([]model.WordSuggestionResponse, 0)
+	var matches []store.PrefixEntry
+
+	langs := filterLangs(opts.Langs, opts.ExcludeLangs)
+	switch {
+	case len(opts.Langs) > 0 && len(langs) == 0:
+		// Every requested language is excluded
+		return page, nil
+	case len(langs) == 1:
+		matches, page.NextCursor, err = cs.rangePrefix(ctx, version, langs[0], prefix, cursor, limit)
+	case len(langs) > 1:
+		matches, page.NextCursor, err = cs.mergeLangPrefixes(ctx, version, prefix, langs, cursor, limit)
+	case len(opts.ExcludeLangs) > 0:
+		matches, page.NextCursor, err = cs.scanPrefixExcluding(ctx, version, prefix, opts.ExcludeLangs, cursor, limit)
+	default:
+		matches, page.NextCursor, err = cs.rangePrefix(ctx, version, "", prefix, cursor, limit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch suggestions: %w", err)
+	}
 
+	resolver := cs.newLanguageResolver()
 	for _, match := range matches {
-		// match format: "word|language|conceptID"
-		parts := strings.Split(match, "|")
-		if len(parts) != 3 {
-			continue
+		page.Suggestions = append(page.Suggestions, model.WordSuggestionResponse{
+			Word:         match.Word,
+			LanguageInfo: resolver.getLanguageInfo(match.Lang),
+			ConceptID:    match.ConceptID,
+		})
+	}
+	page.MissingLanguages = resolver.missingLanguages()
+
+	if err := cs.attachConcepts(ctx, page.Suggestions); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// attachConcepts adds the concept metadata to suggestions, fetching each
+// concept once
+func (cs *cognateSearch) attachConcepts(ctx context.Context, suggestions []model.WordSuggestionResponse) error {
+	if len(suggestions) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	conceptIDs := make([]string, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		if !seen[suggestion.ConceptID] {
+			seen[suggestion.ConceptID] = true
+			conceptIDs = append(conceptIDs, suggestion.ConceptID)
 		}
+	}
 
-		word := parts[0]
-		if seen[word] {
-			continue
+	concepts, err := cs.store.GetConcepts(ctx, conceptIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range suggestions {
+		if info, ok := concepts[suggestions[i].ConceptID]; ok {
+			suggestions[i].Concept = &info
 		}
-		seen[word] = true
+	}
+	return nil
+}
+
+// conceptInfo returns the metadata of one concept, nil when there is none
+func (cs *cognateSearch) conceptInfo(ctx context.Context, conceptID string) (*model.ConceptInfo, error) {
+	concepts, err := cs.store.GetConcepts(ctx, []string{conceptID})
+	if err != nil {
+		return nil, err
+	}
 
-		langInfo, _ := cs.getLanguageInfo(ctx, parts[1])
+	info, ok := concepts[conceptID]
+	if !ok {
+		return nil, nil
+	}
+	return &info, nil
+}
 
-		suggestions = append(suggestions, model.WordSuggestionResponse{
-			Word:         word,
-			LanguageInfo: langInfo,
-			ConceptID:    parts[2],
-		})
+// filterLangs removes excluded codes from the requested languages, keeping
+// the request order and dropping duplicates
+func filterLangs(langs, exclude []string) []string {
+	excluded := make(map[string]bool, len(exclude))
+	for _, code := range exclude {
+		excluded[code] = true
+	}
 
-		if len(suggestions) >= limit {
-			break
+	filtered := make([]string, 0, len(langs))
+	for _, code := range langs {
+		if excluded[code] {
+			continue
 		}
+		excluded[code] = true
+		filtered = append(filtered, code)
 	}
-
-	return suggestions, nil
+	return filtered
 }
 
-func (cs *cognateSearch) buildChains(cognates []model.Cognate, ctx context.Context) ([]model.CognateChain, error) {
-	// Create a map of connections and store original cognates
-	connections := make(map[string]map[string]model.Cognate)
-	cognateData := make(map[string]model.Cognate) // Store original cognate data
+// rangePrefix reads one page of a single ranked prefix index. The cursor is
+// the rank of the first member.
+func (cs *cognateSearch) rangePrefix(ctx context.Context, version int64, lang, prefix string, cursor int64, limit int) ([]store.PrefixEntry, int64, error) {
+	// Fetch one extra member to know whether another page exists
+	matches, err := cs.store.RangePrefix(ctx, version, lang, prefix, cursor, int64(limit)+1)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	for _, cog := range cognates {
-		// Initialize maps if they don't exist
-		if connections[cog.Lang1+":"+cog.Word1] == nil {
-			connections[cog.Lang1+":"+cog.Word1] = make(map[string]model.Cognate)
+	if len(matches) > limit {
+		return matches[:limit], cursor + int64(limit), nil
+	}
+	return matches, 0, nil
+}
+
+// mergeLangPrefixes reads one page across several per-language prefix
+// indexes. Each index is already ordered, so only the first cursor+limit+1
+// members of each are needed to produce the merged page, and the cursor is
+// the rank in the merged ordering.
+func (cs *cognateSearch) mergeLangPrefixes(ctx context.Context, version int64, prefix string, langs []string, cursor int64, limit int) ([]store.PrefixEntry, int64, error) {
+	stop := cursor + int64(limit)
+
+	// langs holds no code twice and every member includes its language, so
+	// the indexes share no member
+	var merged []store.PrefixEntry
+	for _, lang := range langs {
+		entries, err := cs.store.RangePrefix(ctx, version, lang, prefix, 0, stop+1)
+		if err != nil {
+			return nil, 0, err
 		}
-		if connections[cog.Lang2+":"+cog.Word2] == nil {
-			connections[cog.Lang2+":"+cog.Word2] = make(map[string]model.Cognate)
+		merged = append(merged, entries...)
+	}
+
+	// Same ordering as a single index: score, then member lexicographically
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].Score != merged[j].Score {
+			return merged[i].Score < merged[j].Score
 		}
+		return merged[i].Member() < merged[j].Member()
+	})
+
+	if int64(len(merged)) <= cursor {
+		return []store.PrefixEntry{}, 0, nil
+	}
+
+	var next int64
+	end := int64(len(merged))
+	if end > stop {
+		end = stop
+		next = stop
+	}
 
-		// Store bidirectional connections
-		connections[cog.Lang1+":"+cog.Word1][cog.Lang2+":"+cog.Word2] = cog
-		connections[cog.Lang2+":"+cog.Word2][cog.Lang1+":"+cog.Word1] = cog
+	return merged[cursor:end], next, nil
+}
 
-		// Store original cognate data for both words
-		cognateData[cog.Lang1+":"+cog.Word1] = cog
-		cognateData[cog.Lang2+":"+cog.Word2] = cog
+// scanPrefixExcluding walks the global prefix index in chunks, skipping
+// excluded languages until a page is filled. The cursor is the rank in the
+// global index to resume from.
+func (cs *cognateSearch) scanPrefixExcluding(ctx context.Context, version int64, prefix string, exclude []string, cursor int64, limit int) ([]store.PrefixEntry, int64, error) {
+	excluded := make(map[string]bool, len(exclude))
+	for _, code := range exclude {
+		excluded[code] = true
 	}
 
-	processed := make(map[string]bool)
-	var chains []model.CognateChain
-	coordTracker := newCoordinateTracker()
+	chunk := int64(limit * 4)
+	matches := make([]store.PrefixEntry, 0, limit)
 
-	// Helper function to create chain word
-	createChainWord := func(word, lang string, key string) (model.ChainWord, error) {
-		langInfo, err := cs.getLanguageInfo(ctx, lang)
+	for {
+		entries, err := cs.store.RangePrefix(ctx, version, "", prefix, cursor, chunk)
 		if err != nil {
-			return model.ChainWord{}, err
+			return nil, 0, err
 		}
 
-		adjustedCoords := coordTracker.getAdjustedCoordinates(langInfo.Coordinates)
-		adjustedLangInfo := langInfo
-		adjustedLangInfo.Coordinates = adjustedCoords
-
-		// Get the original cognate data
-		cognate, exists := cognateData[key]
-		var translit string
-		if exists {
-			if key == cognate.Lang1+":"+cognate.Word1 {
-				translit = cognate.Translit1
-			} else if key == cognate.Lang2+":"+cognate.Word2 {
-				translit = cognate.Translit2
+		for i, entry := range entries {
+			if excluded[entry.Lang] {
+				continue
 			}
+			if len(matches) == limit {
+				// A further match exists, resume from it next time
+				return matches, cursor + int64(i), nil
+			}
+			matches = append(matches, entry)
 		}
 
-		return model.ChainWord{
-			Word:         word,
-			Translit1:    translit,
-			LanguageInfo: adjustedLangInfo,
-		}, nil
+		if int64(len(entries)) < chunk {
+			return matches, 0, nil
+		}
+		cursor += chunk
 	}
+}
 
-	// Helper function to build chain recursively
-	var buildChainRecursive func(startWord string, visited map[string]bool) []model.ChainWord
-	buildChainRecursive = func(startWord string, visited map[string]bool) []model.ChainWord {
-		if visited[startWord] {
-			return nil
+// buildChains turns the cognate pairs of a concept into its connected
+// groups of words, largest first
+func (cs *cognateSearch) buildChains(cognates []model.Cognate, resolver *languageResolver) ([]model.CognateChain, error) {
+	graph := newCognateGraph(cognates)
+	positions := cs.markerPositions(graph, resolver)
+
+	chains := make([]model.CognateChain, 0)
+	for _, component := range graph.components() {
+		chain := model.CognateChain{
+			Nodes: make([]model.ChainWord, 0, len(component)),
+			Edges: make([]model.ChainEdge, 0, len(component)-1),
 		}
-		visited[startWord] = true
-
-		parts := strings.Split(startWord, ":")
-		if len(parts) != 2 {
-			return nil
+		for _, key := range component {
+			chain.Nodes = append(chain.Nodes, cs.chainWord(graph, key, resolver, positions))
+		}
+		for _, edge := range graph.componentEdges(component) {
+			chain.Edges = append(chain.Edges, model.ChainEdge{From: edge[0], To: edge[1]})
 		}
+		chains = append(chains, chain)
+	}
 
-		lang, word := parts[0], parts[1]
-		var chain []model.ChainWord
+	return chains, nil
+}
 
-		// Create word for current node with original cognate data
-		wordObj, err := createChainWord(word, lang, startWord)
-		if err == nil {
-			chain = append(chain, wordObj)
+// markerPositions lays out the map markers of every word of a concept's
+// graph, see placeMarkers. Responses showing only some of the words use the
+// same layout, so a word is at the same place in all of them. Languages are
+// resolved through the response's resolver, as chainWord does.
+func (cs *cognateSearch) markerPositions(graph *cognateGraph, resolver *languageResolver) map[string][]float64 {
+	origins := make(map[string][]float64, len(graph.nodes))
+	for key, node := range graph.nodes {
+		// Unknown languages have no coordinates
+		if info, ok := resolver.lookup(node.Lang); ok {
+			origins[key] = info.Coordinates
 		}
+	}
+	return placeMarkers(origins)
+}
 
-		// Explore connections
-		for nextWord := range connections[startWord] {
-			if !visited[nextWord] {
-				subChain := buildChainRecursive(nextWord, visited)
-				if len(subChain) > 0 {
-					chain = append(chain, subChain...)
-				}
-			}
-		}
+// chainWord resolves a graph node into a chain word placed at its marker
+// position, keeping the coordinates of its language alongside
+func (cs *cognateSearch) chainWord(graph *cognateGraph, key string, resolver *languageResolver, positions map[string][]float64) model.ChainWord {
+	node := graph.nodes[key]
+	langInfo := resolver.getLanguageInfo(node.Lang)
+	original := langInfo.Coordinates
+	if position, ok := positions[key]; ok {
+		langInfo.Coordinates = position
+	}
 
-		return chain
+	return model.ChainWord{
+		ID:                  key,
+		Word:                node.Word,
+		Translit1:           node.Translit,
+		LanguageInfo:        langInfo,
+		OriginalCoordinates: original,
 	}
+}
 
-	// Find starting points
-	for startWord := range connections {
-		if !processed[startWord] {
-			visited := make(map[string]bool)
-			chain := buildChainRecursive(startWord, visited)
+// loadConcept reads the stored cognate pairs of a concept, ordered by pair
+func (cs *cognateSearch) loadConcept(ctx context.Context, conceptID string) ([]model.Cognate, error) {
+	version, err := cs.store.ActiveVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-			// Mark all words in chain as processed
-			for _, word := range chain {
-				processed[word.LanguageInfo.Code+":"+word.Word] = true
-			}
+	return cs.store.GetConcept(ctx, version, conceptID)
+}
 
-			if len(chain) > 0 {
-				chains = append(chains, model.CognateChain{Chain: chain})
-			}
-		}
+// FindByConceptID returns all cognates for a concept ID along with the
+// concept's metadata
+func (cs *cognateSearch) FindByConceptID(ctx context.Context, conceptID string) (*model.ConceptResponse, error) {
+	cognates, err := cs.loadConcept(ctx, conceptID)
+	if err != nil {
+		return nil, err
 	}
 
-	return chains, nil
+	concept, err := cs.conceptInfo(ctx, conceptID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ConceptResponse{
+		Cognates: cognates,
+		Concept:  concept,
+	}, nil
 }
 
-// FindByConceptID returns all cognates for a concept ID
-func (cs *cognateSearch) FindByConceptID(ctx context.Context, conceptID string) ([]model.Cognate, error) {
-	jsonStrings, err := cs.redisClient.LRange(ctx, fmt.Sprintf("concept:%s", conceptID), 0, -1).Result()
+// FindByWord returns every concept the exact word participates in, optionally
+// restricted to a single language
+func (cs *cognateSearch) FindByWord(ctx context.Context, word, lang string) (*model.WordLookupResponse, error) {
+	version, err := cs.store.ActiveVersion(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch cognates: %w", err)
+		return nil, err
 	}
 
-	if len(jsonStrings) == 0 {
-		return []model.Cognate{}, nil
+	senses, err := cs.store.WordSenses(ctx, version, word)
+	if err != nil {
+		return nil, err
 	}
 
-	cognates := make([]model.Cognate, 0, len(jsonStrings))
-	for _, jsonStr := range jsonStrings {
-		var cognate model.Cognate
-		if err := json.Unmarshal([]byte(jsonStr), &cognate); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal cognate: %w", err)
+	// Senses come back in arbitrary order, keep the response stable
+	sort.Slice(senses, func(i, j int) bool {
+		if senses[i].ConceptID != senses[j].ConceptID {
+			return senses[i].ConceptID < senses[j].ConceptID
+		}
+		return senses[i].Lang < senses[j].Lang
+	})
+
+	response := &model.WordLookupResponse{Results: make([]model.WordSuggestionResponse, 0, len(senses))}
+	resolver := cs.newLanguageResolver()
+
+	for _, sense := range senses {
+		if lang != "" && sense.Lang != lang {
+			continue
 		}
-		cognates = append(cognates, cognate)
+
+		response.Results = append(response.Results, model.WordSuggestionResponse{
+			Word:         word,
+			ConceptID:    sense.ConceptID,
+			LanguageInfo: resolver.getLanguageInfo(sense.Lang),
+		})
 	}
+	response.MissingLanguages = resolver.missingLanguages()
 
-	return cognates, nil
+	if err := cs.attachConcepts(ctx, response.Results); err != nil {
+		return nil, err
+	}
+
+	return response, nil
 }
 
-func (cs *cognateSearch) FindCognateChains(ctx context.Context, conceptID, word, lang string) (*model.CognateChainResponse, error) {
-	jsonStrings, err := cs.redisClient.LRange(ctx, fmt.Sprintf("concept:%s", conceptID), 0, -1).Result()
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch cognates: %w", err)
+// chainLangs returns the language codes of the words of a chain
+func chainLangs(chain model.CognateChain) []string {
+	langs := make([]string, 0, len(chain.Nodes))
+	for _, chainWord := range chain.Nodes {
+		langs = append(langs, chainWord.LanguageInfo.Code)
 	}
+	return langs
+}
 
-	var cognates []model.Cognate
-	for _, jsonStr := range jsonStrings {
-		var cognate model.Cognate
-		if err := json.Unmarshal([]byte(jsonStr), &cognate); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal cognate: %w", err)
+// chainHasWord reports whether a chain holds the word with the node key
+func chainHasWord(chain model.CognateChain, key string) bool {
+	for _, chainWord := range chain.Nodes {
+		if chainWord.ID == key {
+			return true
 		}
-		cognates = append(cognates, cognate)
+	}
+	return false
+}
+
+func (cs *cognateSearch) FindCognateChains(ctx context.Context, conceptID string, opts ChainOptions) (*model.CognateChainResponse, error) {
+	cognates, err := cs.loadConcept(ctx, conceptID)
+	if err != nil {
+		return nil, err
 	}
 
-	chains, err := cs.buildChains(cognates, ctx)
+	resolver := cs.newLanguageResolver()
+	chains, err := cs.buildChains(cognates, resolver)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build chains: %w", err)
 	}
 
-	// If word and language are provided, filter for specific chain
-	if word != "" && lang != "" {
-		var filteredChains []model.CognateChain
+	// If word and language are provided, keep the chain holding that word
+	if opts.Word != "" && opts.Lang != "" {
+		target := nodeKey(opts.Lang, opts.Word)
+		filteredChains := make([]model.CognateChain, 0, 1)
 		for _, chain := range chains {
-			// Check if this chain contains the specified word and language
-			containsTarget := false
-			for _, chainWord := range chain.Chain {
-				if chainWord.Word == word && chainWord.LanguageInfo.Code == lang {
-					containsTarget = true
-					break
-				}
-			}
-			if containsTarget {
+			if chainHasWord(chain, target) {
 				filteredChains = append(filteredChains, chain)
-				break // We only need the first matching chain
+				break
 			}
 		}
 		chains = filteredChains
 	}
 
-	return &model.CognateChainResponse{
-		ConceptID: conceptID,
-		Chains:    chains,
-	}, nil
+	concept, err := cs.conceptInfo(ctx, conceptID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.CognateChainResponse{
+		ConceptID:        conceptID,
+		Concept:          concept,
+		Chains:           chains,
+		MissingLanguages: chainMissingLanguages(chains),
+	}
+
+	if opts.Stats {
+		var allLangs []string
+		for i := range chains {
+			langs := chainLangs(chains[i])
+			chains[i].Stats = geoStats(langs, resolver)
+			allLangs = append(allLangs, langs...)
+		}
+		response.Stats = geoStats(allLangs, resolver)
+	}
+
+	return response, nil
 }
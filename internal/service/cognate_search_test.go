@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"cognet-world-inquiry-service/internal/model"
+	"cognet-world-inquiry-service/internal/store"
+)
+
+// newTestSearch stores cognates as the active version of a memory store
+func newTestSearch(t *testing.T, cognates []model.Cognate) (CognateSearch, store.CognateStore) {
+	t.Helper()
+
+	ctx := context.Background()
+	cognateStore := store.NewMemoryStore()
+	importer := &dataImporter{store: cognateStore}
+	if _, err := importer.writeCognates(ctx, 1, cognates, ImportModeAppend); err != nil {
+		t.Fatal(err)
+	}
+	if err := cognateStore.SetActiveVersion(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	return NewCognateSearch(cognateStore, NewLanguageRegistry(cognateStore)), cognateStore
+}
+
+func TestGetWordSuggestionsPaging(t *testing.T) {
+	var cognates []model.Cognate
+	for i := 0; i < 12; i++ {
+		cognates = append(cognates, model.Cognate{
+			ConceptID: fmt.Sprintf("n%08d", i%4),
+			Lang1:     "eng", Word1: fmt.Sprintf("ball%d", i%5),
+			Lang2: "deu", Word2: fmt.Sprintf("ball%d", i%3),
+		}, model.Cognate{
+			ConceptID: fmt.Sprintf("n%08d", i%4),
+			Lang1:     "fra", Word1: fmt.Sprintf("balle%d", i),
+			Lang2: "ita", Word2: "palla",
+		})
+	}
+	search, _ := newTestSearch(t, cognates)
+
+	tests := []struct {
+		name string
+		opts SuggestionOptions
+	}{
+		{name: "all languages", opts: SuggestionOptions{}},
+		{name: "one language", opts: SuggestionOptions{Langs: []string{"eng"}}},
+		{name: "merged languages", opts: SuggestionOptions{Langs: []string{"eng", "deu", "fra"}}},
+		{name: "merged with repeated language", opts: SuggestionOptions{Langs: []string{"eng", "fra", "eng"}}},
+		{name: "excluded language", opts: SuggestionOptions{ExcludeLangs: []string{"fra"}}},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			all := tt.opts
+			all.Limit = MaxSuggestionLimit
+			want, err := search.GetWordSuggestions(ctx, "ba", all)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want.NextCursor != 0 {
+				t.Fatalf("single page has next cursor %d", want.NextCursor)
+			}
+
+			var got []model.WordSuggestionResponse
+			seen := make(map[string]bool)
+			paged := tt.opts
+			paged.Limit = 3
+			for pages := 0; ; pages++ {
+				if pages > len(want.Suggestions) {
+					t.Fatal("cursor does not advance")
+				}
+				page, err := search.GetWordSuggestions(ctx, "ba", paged)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, suggestion := range page.Suggestions {
+					key := suggestion.Word + "|" + suggestion.LanguageInfo.Code + "|" + suggestion.ConceptID
+					if seen[key] {
+						t.Errorf("duplicate suggestion %s", key)
+					}
+					seen[key] = true
+				}
+				got = append(got, page.Suggestions...)
+				if page.NextCursor == 0 {
+					break
+				}
+				paged.Cursor = page.NextCursor
+			}
+
+			if len(got) != len(want.Suggestions) {
+				t.Fatalf("paged %d suggestions, want %d", len(got), len(want.Suggestions))
+			}
+			for i := range got {
+				if got[i].Word != want.Suggestions[i].Word || got[i].ConceptID != want.Suggestions[i].ConceptID ||
+					got[i].LanguageInfo.Code != want.Suggestions[i].LanguageInfo.Code {
+					t.Errorf("suggestion %d = %s/%s, want %s/%s", i, got[i].Word, got[i].ConceptID, want.Suggestions[i].Word, want.Suggestions[i].ConceptID)
+				}
+			}
+		})
+	}
+}
+
+func TestGetWordSuggestionsRanking(t *testing.T) {
+	search, _ := newTestSearch(t, []model.Cognate{
+		{ConceptID: "n00000001", Lang1: "eng", Word1: "bank", Lang2: "deu", Word2: "bankett"},
+		{ConceptID: "n00000001", Lang1: "eng", Word1: "bank", Lang2: "fra", Word2: "banque"},
+		{ConceptID: "n00000002", Lang1: "eng", Word1: "ban", Lang2: "deu", Word2: "bann"},
+	})
+
+	page, err := search.GetWordSuggestions(context.Background(), "ban", SuggestionOptions{Langs: []string{"eng", "deu"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Shorter words first, then the words with more cognates
+	want := []string{"ban", "bank", "bann", "bankett"}
+	if len(page.Suggestions) != len(want) {
+		t.Fatalf("got %d suggestions, want %d", len(page.Suggestions), len(want))
+	}
+	for i, word := range want {
+		if page.Suggestions[i].Word != word {
+			t.Errorf("suggestion %d = %q, want %q", i, page.Suggestions[i].Word, word)
+		}
+	}
+}
+
+func TestGetWordSuggestionsPerConcept(t *testing.T) {
+	search, cognateStore := newTestSearch(t, []model.Cognate{
+		{ConceptID: "n08420278", Lang1: "eng", Word1: "bank", Lang2: "deu", Word2: "Bank"},
+		{ConceptID: "n09213565", Lang1: "eng", Word1: "bank", Lang2: "deu", Word2: "Ufer"},
+		{ConceptID: "n09213565", Lang1: "eng", Word1: "bank", Lang2: "fra", Word2: "rive"},
+	})
+	ctx := context.Background()
+	if err := cognateStore.SaveConcepts(ctx, []model.ConceptInfo{
+		{ConceptID: "n08420278", Gloss: "a financial institution"},
+		{ConceptID: "n09213565", Gloss: "sloping land beside a body of water"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// One suggestion per concept of the word, its senses told apart by the
+	// concept, on one page or paged
+	want := []string{"n09213565", "n08420278"}
+	for _, limit := range []int{MaxSuggestionLimit, 1} {
+		var got []model.WordSuggestionResponse
+		opts := SuggestionOptions{Langs: []string{"eng"}, Limit: limit}
+		for {
+			page, err := search.GetWordSuggestions(ctx, "bank", opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, page.Suggestions...)
+			if page.NextCursor == 0 || len(got) > len(want) {
+				break
+			}
+			opts.Cursor = page.NextCursor
+		}
+
+		if len(got) != len(want) {
+			t.Fatalf("limit %d: got %d suggestions, want %d", limit, len(got), len(want))
+		}
+		for i, conceptID := range want {
+			if got[i].Word != "bank" || got[i].ConceptID != conceptID {
+				t.Errorf("limit %d: suggestion %d = %s/%s, want bank/%s", limit, i, got[i].Word, got[i].ConceptID, conceptID)
+			}
+			if got[i].Concept == nil || got[i].Concept.ConceptID != conceptID {
+				t.Errorf("limit %d: suggestion %d has concept %+v", limit, i, got[i].Concept)
+			}
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"cognet-world-inquiry-service/internal/model"
+)
+
+var ErrInvalidConcept = errors.New("invalid concept")
+
+// wordNetPOS are the part of speech letters of WordNet synsets. The concept
+// ID starts with the same letter.
+var wordNetPOS = map[string]bool{"n": true, "v": true, "a": true, "s": true, "r": true}
+
+// ValidateConcept checks concept metadata before it is stored. A missing
+// part of speech is taken from the concept ID.
+func ValidateConcept(info *model.ConceptInfo) error {
+	if !conceptIDPattern.MatchString(info.ConceptID) {
+		return fmt.Errorf("%w: concept ID %q is not a WordNet synset ID such as n00001234", ErrInvalidConcept, info.ConceptID)
+	}
+
+	idPOS := info.ConceptID[:1]
+	info.POS = strings.ToLower(strings.TrimSpace(info.POS))
+	switch {
+	case info.POS == "":
+		info.POS = idPOS
+	case !wordNetPOS[info.POS]:
+		return fmt.Errorf("%w: part of speech %q of %s, expected n, v, a, s or r", ErrInvalidConcept, info.POS, info.ConceptID)
+	case info.POS != idPOS:
+		return fmt.Errorf("%w: part of speech %q does not match concept ID %s", ErrInvalidConcept, info.POS, info.ConceptID)
+	}
+
+	info.Gloss = strings.TrimSpace(info.Gloss)
+	return nil
+}
+
+// ImportConcepts stores concept metadata from a JSON array or from JSON
+// lines, optionally gzip or zip compressed, and returns the number of
+// concepts stored. Concepts are decoded one by one, so the whole of WordNet
+// can be imported without holding it in memory.
+func (d *dataImporter) ImportConcepts(ctx context.Context, reader io.Reader) (int64, error) {
+	d.setStatus("importing concepts")
+	defer d.setStatus("ready")
+
+	input, err := openImportInput(reader, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer input.Close()
+
+	array, err := isJSONArray(input.Reader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read concepts file: %w", err)
+	}
+
+	decoder := json.NewDecoder(input.Reader)
+	if array {
+		// Consume the opening bracket
+		if _, err := decoder.Token(); err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrInvalidConcept, err)
+		}
+	}
+
+	batchSize := 1000
+	batch := make([]model.ConceptInfo, 0, batchSize)
+	var imported int64
+
+	flush := func() error {
+		if err := d.store.SaveConcepts(ctx, batch); err != nil {
+			return err
+		}
+		if err := d.indexConcepts(ctx, batch); err != nil {
+			return err
+		}
+		imported += int64(len(batch))
+		batch = batch[:0]
+		return nil
+	}
+
+	for entry := 1; ; entry++ {
+		if array && !decoder.More() {
+			break
+		}
+
+		var info model.ConceptInfo
+		err := decoder.Decode(&info)
+		if err == io.EOF && !array {
+			break
+		}
+		if err != nil {
+			return imported, fmt.Errorf("entry %d: %w: %v", entry, ErrInvalidConcept, err)
+		}
+		if err := ValidateConcept(&info); err != nil {
+			return imported, fmt.Errorf("entry %d: %w", entry, err)
+		}
+
+		batch = append(batch, info)
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return imported, err
+			}
+		}
+	}
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return imported, err
+		}
+	}
+
+	metadata := map[string]interface{}{
+		"total_concepts": imported,
+		"status":         "completed",
+		"timestamp":      time.Now().Unix(),
+	}
+	if err := d.store.SaveMetadata(ctx, "concepts", metadata); err != nil {
+		return imported, fmt.Errorf("failed to store concept import metadata: %w", err)
+	}
+
+	return imported, nil
+}
+
+// isJSONArray reports whether the JSON in reader starts with an array,
+// leaving the reader at its first non-space byte
+func isJSONArray(reader *bufio.Reader) (bool, error) {
+	for {
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case 0xEF:
+			// UTF-8 byte order mark, skip the remaining two bytes
+			reader.Discard(2)
+			continue
+		}
+		return b == '[', reader.UnreadByte()
+	}
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cognet-world-inquiry-service/internal/model"
+)
+
+func gzipped(t *testing.T, data string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestImportConceptsFormats(t *testing.T) {
+	bank := `{"concept_id": "n08420278", "pos": "N", "lemmas": ["bank", "depository_financial_institution"], "gloss": " a financial institution "}`
+	run := `{"concept_id": "v01926311", "lemmas": ["run"], "gloss": "move fast by using one's feet", "examples": ["Don't run!"]}`
+	want := map[string]model.ConceptInfo{
+		"n08420278": {ConceptID: "n08420278", POS: "n", Lemmas: []string{"bank", "depository_financial_institution"}, Gloss: "a financial institution"},
+		"v01926311": {ConceptID: "v01926311", POS: "v", Lemmas: []string{"run"}, Gloss: "move fast by using one's feet", Examples: []string{"Don't run!"}},
+	}
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "json array", input: "[" + bank + ",\n" + run + "]"},
+		{name: "json array with byte order mark", input: "\ufeff  \n[" + bank + "," + run + "]\n"},
+		{name: "json lines", input: bank + "\n" + run + "\n"},
+		{name: "json lines without final newline", input: "\n" + bank + "\n\n" + run},
+		{name: "gzip json lines", input: gzipped(t, bank+"\n"+run+"\n")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			importer, cognateStore := newTestImporter()
+			imported, err := importer.ImportConcepts(ctx, strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if imported != 2 {
+				t.Errorf("imported %d concepts, want 2", imported)
+			}
+
+			concepts, err := cognateStore.GetConcepts(ctx, []string{"n08420278", "v01926311"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(concepts, want) {
+				t.Errorf("stored %+v, want %+v", concepts, want)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "[]", " \n"} {
+		importer, _ := newTestImporter()
+		if imported, err := importer.ImportConcepts(context.Background(), strings.NewReader(input)); err != nil || imported != 0 {
+			t.Errorf("ImportConcepts(%q) = %d, %v, want nothing imported", input, imported, err)
+		}
+	}
+}
+
+func TestValidateConcept(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    model.ConceptInfo
+		pos     string
+		wantErr bool
+	}{
+		{name: "part of speech from the ID", info: model.ConceptInfo{ConceptID: "a00001740"}, pos: "a"},
+		{name: "part of speech normalized", info: model.ConceptInfo{ConceptID: "r00001740", POS: " R "}, pos: "r"},
+		{name: "satellite adjective", info: model.ConceptInfo{ConceptID: "s00001740", POS: "s"}, pos: "s"},
+		{name: "unknown part of speech", info: model.ConceptInfo{ConceptID: "n00001740", POS: "noun"}, wantErr: true},
+		{name: "part of speech of another synset", info: model.ConceptInfo{ConceptID: "n00001740", POS: "v"}, wantErr: true},
+		{name: "synset without part of speech", info: model.ConceptInfo{ConceptID: "00001740"}, wantErr: true},
+		{name: "synset with unknown part of speech", info: model.ConceptInfo{ConceptID: "x00001740"}, wantErr: true},
+		{name: "synset with short offset", info: model.ConceptInfo{ConceptID: "n0001740"}, wantErr: true},
+		{name: "WordNet sense key", info: model.ConceptInfo{ConceptID: "bank%1:14:00::"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := tt.info
+			err := ValidateConcept(&info)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidConcept) {
+					t.Fatalf("ValidateConcept(%+v) error = %v, want ErrInvalidConcept", tt.info, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.POS != tt.pos {
+				t.Errorf("part of speech = %q, want %q", info.POS, tt.pos)
+			}
+		})
+	}
+}
+
+func TestImportConceptsRejects(t *testing.T) {
+	valid := `{"concept_id": "n08420278", "lemmas": ["bank"]}`
+
+	tests := []struct {
+		name  string
+		input string
+		entry string
+	}{
+		{name: "part of speech in json array", input: "[" + valid + `, {"concept_id": "n09213565", "pos": "v"}]`, entry: "entry 2"},
+		{name: "synset in json lines", input: valid + "\n" + valid + "\n" + `{"concept_id": "09213565"}`, entry: "entry 3"},
+		{name: "malformed json lines", input: valid + "\n{\"concept_id\": ", entry: "entry 2"},
+		{name: "unterminated json array", input: "[" + valid + ",", entry: "entry 2"},
+		{name: "json array of strings", input: `["n08420278"]`, entry: "entry 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			importer, _ := newTestImporter()
+			_, err := importer.ImportConcepts(context.Background(), strings.NewReader(tt.input))
+			if !errors.Is(err, ErrInvalidConcept) {
+				t.Fatalf("ImportConcepts() error = %v, want ErrInvalidConcept", err)
+			}
+			if !strings.HasPrefix(err.Error(), tt.entry+":") {
+				t.Errorf("ImportConcepts() error = %q, want it to name %s", err, tt.entry)
+			}
+		})
+	}
+}
+
+func TestConceptMetadataInResponses(t *testing.T) {
+	ctx := context.Background()
+	search, cognateStore := newTestSearch(t, []model.Cognate{
+		{ConceptID: "n08420278", Lang1: "eng", Word1: "bank", Lang2: "deu", Word2: "Bank"},
+		{ConceptID: "n08420278", Lang1: "deu", Word1: "Bank", Lang2: "fra", Word2: "banque"},
+		// No metadata is imported for the river bank
+		{ConceptID: "n09213565", Lang1: "eng", Word1: "bank", Lang2: "deu", Word2: "Ufer"},
+	})
+	importer := NewDataImporter(cognateStore, NewLanguageRegistry(cognateStore)).(*dataImporter)
+	if _, err := importer.ImportConcepts(ctx, strings.NewReader(`{"concept_id": "n08420278", "lemmas": ["bank"], "gloss": "a financial institution"}`)); err != nil {
+		t.Fatal(err)
+	}
+	want := &model.ConceptInfo{ConceptID: "n08420278", POS: "n", Lemmas: []string{"bank"}, Gloss: "a financial institution"}
+
+	check := func(name, conceptID string, got *model.ConceptInfo) {
+		t.Helper()
+		if conceptID == want.ConceptID && !reflect.DeepEqual(got, want) {
+			t.Errorf("%s of %s has concept %+v, want %+v", name, conceptID, got, want)
+		}
+		if conceptID != want.ConceptID && got != nil {
+			t.Errorf("%s of %s has concept %+v without metadata", name, conceptID, got)
+		}
+	}
+
+	for _, conceptID := range []string{"n08420278", "n09213565"} {
+		concept, err := search.FindByConceptID(ctx, conceptID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check("concept", conceptID, concept.Concept)
+
+		chains, err := search.FindCognateChains(ctx, conceptID, ChainOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		check("chains", conceptID, chains.Concept)
+
+		geo, err := search.FindConceptGeo(ctx, conceptID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check("geo", conceptID, geo.Concept)
+
+		path, err := search.FindPath(ctx, conceptID, "eng", "bank", "deu", concept.Cognates[0].Word2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check("path", conceptID, path.Concept)
+	}
+
+	suggestions, err := search.GetWordSuggestions(ctx, "ban", SuggestionOptions{Langs: []string{"eng"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	words, err := search.FindByWord(ctx, "bank", "eng")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, results := range map[string][]model.WordSuggestionResponse{"suggestion": suggestions.Suggestions, "word": words.Results} {
+		if len(results) != 2 {
+			t.Fatalf("%d %s results, want one per concept", len(results), name)
+		}
+		for _, result := range results {
+			check(name, result.ConceptID, result.Concept)
+		}
+	}
+
+	graph, err := search.FindWordGraph(ctx, "bank", "eng", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Concepts) != 2 {
+		t.Fatalf("word graph has %d concepts, want 2", len(graph.Concepts))
+	}
+	for _, concept := range graph.Concepts {
+		check("word graph", concept.ConceptID, concept.Concept)
+	}
+}
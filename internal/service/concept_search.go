@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"cognet-world-inquiry-service/internal/model"
+	"cognet-world-inquiry-service/internal/store"
+	"cognet-world-inquiry-service/internal/text"
+)
+
+// ConceptSearchOptions pages through the ranked concepts of a text query
+type ConceptSearchOptions struct {
+	Limit  int   // page size, DefaultSuggestionLimit when zero
+	Cursor int64 // position to resume from, 0 for the first page
+}
+
+// BM25 parameters, the usual defaults
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// conceptText is the text of a concept that is indexed for search: its
+// lemmas, with WordNet's underscores read as spaces, and its gloss
+func conceptText(info model.ConceptInfo) string {
+	lemmas := strings.ReplaceAll(strings.Join(info.Lemmas, " "), "_", " ")
+	return lemmas + " " + info.Gloss
+}
+
+// indexConcepts replaces the search terms of the concepts. When a batch
+// holds a concept twice the last entry wins, as it does when saving.
+func (d *dataImporter) indexConcepts(ctx context.Context, concepts []model.ConceptInfo) error {
+	position := make(map[string]int, len(concepts))
+	docs := make([]store.ConceptTerms, 0, len(concepts))
+	for _, info := range concepts {
+		doc := store.ConceptTerms{ConceptID: info.ConceptID, Terms: text.Frequencies(conceptText(info))}
+		if i, ok := position[info.ConceptID]; ok {
+			docs[i] = doc
+			continue
+		}
+		position[info.ConceptID] = len(docs)
+		docs = append(docs, doc)
+	}
+	return d.store.IndexConceptTerms(ctx, docs)
+}
+
+// SearchConcepts ranks concepts by how well their lemmas and gloss match the
+// query, using BM25 over stemmed English terms
+func (cs *cognateSearch) SearchConcepts(ctx context.Context, query string, opts ConceptSearchOptions) (*model.ConceptSearchPage, error) {
+	page := &model.ConceptSearchPage{Results: []model.ConceptSearchResult{}}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultSuggestionLimit
+	}
+	if limit > MaxSuggestionLimit {
+		limit = MaxSuggestionLimit
+	}
+	cursor := opts.Cursor
+	if cursor < 0 {
+		cursor = 0
+	}
+
+	terms := uniqueTerms(text.Terms(query))
+	if len(terms) == 0 {
+		return page, nil
+	}
+
+	matches, err := cs.store.MatchConceptTerms(ctx, terms)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search concepts: %w", err)
+	}
+
+	scores := bm25Scores(matches, terms)
+	ranked := make([]string, 0, len(scores))
+	for conceptID := range scores {
+		ranked = append(ranked, conceptID)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if scores[ranked[i]] != scores[ranked[j]] {
+			return scores[ranked[i]] > scores[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+
+	page.Total = len(ranked)
+	if cursor >= int64(len(ranked)) {
+		return page, nil
+	}
+	end := cursor + int64(limit)
+	if end < int64(len(ranked)) {
+		page.NextCursor = end
+	} else {
+		end = int64(len(ranked))
+	}
+	ranked = ranked[cursor:end]
+
+	concepts, err := cs.store.GetConcepts(ctx, ranked)
+	if err != nil {
+		return nil, err
+	}
+	for _, conceptID := range ranked {
+		info, ok := concepts[conceptID]
+		if !ok {
+			info = model.ConceptInfo{ConceptID: conceptID}
+		}
+		page.Results = append(page.Results, model.ConceptSearchResult{
+			Concept: info,
+			Score:   math.Round(scores[conceptID]*1e4) / 1e4,
+		})
+	}
+	return page, nil
+}
+
+// bm25Scores scores every concept holding at least one of the terms
+func bm25Scores(matches *store.TermMatches, terms []string) map[string]float64 {
+	scores := make(map[string]float64)
+	if matches.Concepts == 0 {
+		return scores
+	}
+
+	docs := float64(matches.Concepts)
+	avgLength := float64(matches.TotalLength) / docs
+	if avgLength == 0 {
+		avgLength = 1
+	}
+
+	for _, term := range terms {
+		postings := matches.Postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		n := float64(len(postings))
+		idf := math.Log(1 + (docs-n+0.5)/(n+0.5))
+		for conceptID, frequency := range postings {
+			tf := float64(frequency)
+			norm := 1 - bm25B + bm25B*float64(matches.Lengths[conceptID])/avgLength
+			scores[conceptID] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+	return scores
+}
+
+// uniqueTerms drops repeated query terms, keeping their order
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	unique := terms[:0]
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+	return unique
+}
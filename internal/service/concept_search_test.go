@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"cognet-world-inquiry-service/internal/model"
+	"cognet-world-inquiry-service/internal/store"
+)
+
+// newConceptSearch indexes concepts in a memory store
+func newConceptSearch(t *testing.T, concepts []model.ConceptInfo) (CognateSearch, *dataImporter) {
+	t.Helper()
+
+	cognateStore := store.NewMemoryStore()
+	importer := &dataImporter{store: cognateStore}
+	saveConcepts(t, importer, concepts)
+	return NewCognateSearch(cognateStore, NewLanguageRegistry(cognateStore)), importer
+}
+
+func saveConcepts(t *testing.T, importer *dataImporter, concepts []model.ConceptInfo) {
+	t.Helper()
+
+	ctx := context.Background()
+	if err := importer.store.SaveConcepts(ctx, concepts); err != nil {
+		t.Fatal(err)
+	}
+	if err := importer.indexConcepts(ctx, concepts); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// conceptIDs lists the concepts of a page in order
+func conceptIDs(page *model.ConceptSearchPage) []string {
+	ids := make([]string, 0, len(page.Results))
+	for _, result := range page.Results {
+		ids = append(ids, result.Concept.ConceptID)
+	}
+	return ids
+}
+
+var searchConcepts = []model.ConceptInfo{
+	{ConceptID: "n02121620", Lemmas: []string{"cat", "true_cat"}, Gloss: "feline mammal usually having thick soft fur"},
+	{ConceptID: "n02121808", Lemmas: []string{"house_cat", "domestic_cat"}, Gloss: "any domesticated member of the genus Felis"},
+	{ConceptID: "n02084071", Lemmas: []string{"dog", "domestic_dog"}, Gloss: "a member of the genus Canis"},
+	{ConceptID: "n02512053", Lemmas: []string{"fish"}, Gloss: "any of various mostly cold-blooded aquatic vertebrates"},
+	{ConceptID: "n02391049", Lemmas: []string{"zebra"}, Gloss: "striped equine"},
+	{ConceptID: "n02391373", Lemmas: []string{"zebra"}, Gloss: "striped equine"},
+}
+
+func TestSearchConceptsRanking(t *testing.T) {
+	search, _ := newConceptSearch(t, searchConcepts)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// Both terms twice beats one term twice, which beats one term once
+		{"domestic cat", []string{"n02121808", "n02121620", "n02084071"}},
+		// Stemmed, so plurals and other forms match, and with the same
+		// term frequency the shorter text ranks first
+		{"cats", []string{"n02121808", "n02121620"}},
+		{"domestication", []string{"n02121808", "n02084071"}},
+		// Equal scores are ordered by concept ID
+		{"zebra", []string{"n02391049", "n02391373"}},
+		{"the of", []string{}},
+		{"unicorn", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			page, err := search.SearchConcepts(context.Background(), tt.query, ConceptSearchOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := conceptIDs(page)
+			if len(got) != len(tt.want) {
+				t.Fatalf("SearchConcepts(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("SearchConcepts(%q) = %v, want %v", tt.query, got, tt.want)
+				}
+			}
+			if page.Total != len(tt.want) {
+				t.Errorf("total = %d, want %d", page.Total, len(tt.want))
+			}
+			for i := 1; i < len(page.Results); i++ {
+				if page.Results[i].Score > page.Results[i-1].Score {
+					t.Errorf("result %d scores higher than the one before it", i)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchConceptsPaging(t *testing.T) {
+	search, _ := newConceptSearch(t, searchConcepts)
+	ctx := context.Background()
+
+	all, err := search.SearchConcepts(ctx, "domestic cat member", ConceptSearchOptions{Limit: MaxSuggestionLimit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := conceptIDs(all)
+	if len(want) != 3 {
+		t.Fatalf("got %d matches, want 3", len(want))
+	}
+
+	tests := []struct {
+		cursor     int64
+		want       []string
+		nextCursor int64
+	}{
+		{cursor: 0, want: want[:2], nextCursor: 2},
+		{cursor: 2, want: want[2:], nextCursor: 0},
+		{cursor: 3, want: []string{}, nextCursor: 0},
+		{cursor: 10, want: []string{}, nextCursor: 0},
+	}
+
+	for _, tt := range tests {
+		page, err := search.SearchConcepts(ctx, "domestic cat member", ConceptSearchOptions{Limit: 2, Cursor: tt.cursor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := conceptIDs(page)
+		if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+			t.Errorf("cursor %d: got %v, want %v", tt.cursor, got, tt.want)
+		}
+		if page.NextCursor != tt.nextCursor {
+			t.Errorf("cursor %d: next cursor = %d, want %d", tt.cursor, page.NextCursor, tt.nextCursor)
+		}
+		if page.Total != 3 {
+			t.Errorf("cursor %d: total = %d, want 3", tt.cursor, page.Total)
+		}
+	}
+}
+
+func TestSearchConceptsReindex(t *testing.T) {
+	search, importer := newConceptSearch(t, searchConcepts)
+	ctx := context.Background()
+
+	saveConcepts(t, importer, []model.ConceptInfo{
+		{ConceptID: "n02512053", Lemmas: []string{"fish"}, Gloss: "a cold-blooded animal living in water"},
+	})
+
+	for query, want := range map[string]int{"vertebrates": 0, "water": 1, "fish": 1} {
+		page, err := search.SearchConcepts(ctx, query, ConceptSearchOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Total != want {
+			t.Errorf("SearchConcepts(%q) found %d concepts, want %d", query, page.Total, want)
+		}
+	}
+}
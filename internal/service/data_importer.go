@@ -4,26 +4,77 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"strings"
+	"sync"
 	"time"
 
 	"cognet-world-inquiry-service/internal/model"
+	"cognet-world-inquiry-service/internal/store"
 
-	"github.com/redis/go-redis/v9"
+	"github.com/google/uuid"
 )
 
 type DataImporter interface {
-	ImportFromReader(ctx context.Context, reader *bufio.Reader) error
+	ImportFromReader(ctx context.Context, reader io.Reader, opts ImportOptions) (*model.ImportJob, error)
 	ImportLanguages(ctx context.Context, reader *bufio.Reader) error
+	ImportConcepts(ctx context.Context, reader io.Reader) (int64, error)
+	StartImportJob(ctx context.Context, source io.ReadCloser, size int64, opts ImportOptions) (*model.ImportJob, error)
+	GetImportJob(ctx context.Context, id string) (*model.ImportJob, error)
 	GetImportStatus() string
-	ClearDatabase(ctx context.Context) error
+	PrepareClear(ctx context.Context, scope ClearScope) (string, error)
+	ClearDatabase(ctx context.Context, scope ClearScope, token string) (int64, error)
 }
 
+// ImportMode decides what happens to data that is already stored
+type ImportMode string
+
+const (
+	// ImportModeAppend adds new cognate pairs and skips pairs already stored
+	ImportModeAppend ImportMode = "append"
+	// ImportModeUpsert adds new cognate pairs and overwrites stored ones
+	ImportModeUpsert ImportMode = "upsert"
+	// ImportModeReplace builds the new dataset version from the file alone
+	ImportModeReplace ImportMode = "replace"
+)
+
+// ParseImportMode validates a mode name, an empty name means append. It
+// returns the constants rather than name, which may be a request buffer that
+// is reused after the request while the import is still running.
+func ParseImportMode(name string) (ImportMode, error) {
+	switch ImportMode(name) {
+	case "", ImportModeAppend:
+		return ImportModeAppend, nil
+	case ImportModeUpsert:
+		return ImportModeUpsert, nil
+	case ImportModeReplace:
+		return ImportModeReplace, nil
+	default:
+		return "", fmt.Errorf("invalid import mode %q, expected append, upsert or replace", name)
+	}
+}
+
+type ImportOptions struct {
+	Mode ImportMode
+	// DryRun validates the rows and reports rejections without writing
+	DryRun bool
+	// Progress, when set, receives the job after every batch of rows
+	Progress func(job *model.ImportJob)
+}
+
+// importBatchSize is the number of rows written per pipeline, and read
+// between progress reports
+const importBatchSize = 1000
+
 type dataImporter struct {
-	redisClient *redis.Client
-	status      string
+	store     store.CognateStore
+	versions  *datasetVersions
+	languages *LanguageRegistry
+	statusMu  sync.RWMutex
+	status    string
 }
 
 func generatePrefixes(word string) []string {
@@ -38,16 +89,41 @@ func generatePrefixes(word string) []string {
 	return prefixes
 }
 
-func NewDataImporter(redisClient *redis.Client) DataImporter {
+// suggestionLengthWeight separates words of different length in the prefix
+// index score so that length always dominates popularity
+const suggestionLengthWeight = 1_000_000
+
+// suggestionBaseScore is the initial prefix index score of a word. Scores are
+// read in ascending order: the exact match (the shortest possible word for a
+// prefix) comes first, then shorter words, and every cognate pair the word
+// takes part in lowers its score by one.
+func suggestionBaseScore(word string) float64 {
+	return float64(len([]rune(word)) * suggestionLengthWeight)
+}
+
+// wordEntry describes one cognate pair occurrence of a word for the indexes
+func wordEntry(word, lang, conceptID string) store.WordEntry {
+	return store.WordEntry{
+		Word:      word,
+		Lang:      lang,
+		ConceptID: conceptID,
+		Prefixes:  generatePrefixes(word),
+		BaseScore: suggestionBaseScore(word),
+	}
+}
+
+func NewDataImporter(cognateStore store.CognateStore, languages *LanguageRegistry) DataImporter {
 	return &dataImporter{
-		redisClient: redisClient,
-		status:      "ready",
+		store:     cognateStore,
+		versions:  &datasetVersions{store: cognateStore},
+		languages: languages,
+		status:    "ready",
 	}
 }
 
 func (d *dataImporter) ImportLanguages(ctx context.Context, reader *bufio.Reader) error {
-	d.status = "importing languages"
-	defer func() { d.status = "ready" }()
+	d.setStatus("importing languages")
+	defer d.setStatus("ready")
 
 	// Read all data from reader
 	data, err := io.ReadAll(reader)
@@ -58,21 +134,22 @@ func (d *dataImporter) ImportLanguages(ctx context.Context, reader *bufio.Reader
 	// Parse the JSON
 	var languages []model.LanguageInfo
 	if err := json.Unmarshal(data, &languages); err != nil {
-		return fmt.Errorf("failed to parse languages json: %w", err)
+		return fmt.Errorf("%w: failed to parse languages json: %v", ErrInvalidLanguage, err)
 	}
 
-	// Store in Redis
-	pipeline := d.redisClient.Pipeline()
-	for _, info := range languages {
-		jsonData, err := json.Marshal(info)
-		if err != nil {
-			return fmt.Errorf("failed to marshal language info: %w", err)
+	// Nothing is stored when one language is invalid
+	for i, info := range languages {
+		if err := ValidateLanguage(info); err != nil {
+			return fmt.Errorf("language at index %d: %w", i, err)
 		}
-		pipeline.Set(ctx, fmt.Sprintf("lang:%s", info.Code), jsonData, 0)
 	}
 
-	if _, err := pipeline.Exec(ctx); err != nil {
-		return fmt.Errorf("failed to store languages in redis: %w", err)
+	if err := d.store.SaveLanguages(ctx, languages); err != nil {
+		return err
+	}
+
+	if err := d.languages.Invalidate(ctx); err != nil {
+		return fmt.Errorf("failed to refresh languages: %w", err)
 	}
 
 	// Store metadata about language import
@@ -82,122 +159,257 @@ func (d *dataImporter) ImportLanguages(ctx context.Context, reader *bufio.Reader
 		"timestamp":       time.Now().Unix(),
 	}
 
-	metadataJSON, _ := json.Marshal(metadata)
-	if err := d.redisClient.Set(ctx, "import:languages:metadata", metadataJSON, 0).Err(); err != nil {
+	if err := d.store.SaveMetadata(ctx, "languages", metadata); err != nil {
 		return fmt.Errorf("failed to store language import metadata: %w", err)
 	}
 
 	return nil
 }
 
-func (d *dataImporter) ImportFromReader(ctx context.Context, reader *bufio.Reader) error {
-	d.status = "importing"
-	defer func() { d.status = "ready" }()
+// ImportFromReader imports cognate rows synchronously and returns the
+// counters and validation report. reader may hold TSV, CSV or JSON lines as
+// written by Export, plain or gzip or zip compressed.
+func (d *dataImporter) ImportFromReader(ctx context.Context, reader io.Reader, opts ImportOptions) (*model.ImportJob, error) {
+	job := &model.ImportJob{ID: uuid.NewString(), Mode: string(opts.Mode), DryRun: opts.DryRun}
+
+	ctx, leases, err := d.acquireImportLeases(ctx, job.ID, !opts.DryRun)
+	if err != nil {
+		return job, err
+	}
+	defer leases.release(context.WithoutCancel(ctx))
+
+	var onBatch func()
+	if opts.Progress != nil {
+		onBatch = func() { opts.Progress(job) }
+	}
+	err = d.importTSV(ctx, reader, 0, opts, job, onBatch)
+	return job, err
+}
+
+// importTSV imports the cognate rows of source, size bytes long or 0 when
+// unknown, and keeps the counters of job up to date. onBatch, when set, is
+// called after every flushed batch and every importBatchSize rows read.
+// Imports that write must hold the import lease of job.
+func (d *dataImporter) importTSV(ctx context.Context, source io.Reader, size int64, opts ImportOptions, job *model.ImportJob, onBatch func()) error {
+	d.setStatus("importing")
+	defer d.setStatus("ready")
+
+	mode := opts.Mode
+	if mode == "" {
+		mode = ImportModeAppend
+	}
+	job.Mode = string(mode)
+	if job.Report.Rejected == nil {
+		job.Report.Rejected = make(map[string]*model.RejectedRows)
+	}
 
-	// Skip header
-	if _, err := reader.ReadString('\n'); err != nil {
+	input, err := openImportInput(source, size)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	if input.Total > 0 {
+		job.TotalBytes = input.Total
+	}
+
+	header, err := input.Reader.ReadString('\n')
+	if err != nil && (err != io.EOF || header == "") {
 		return fmt.Errorf("failed to read header: %w", err)
 	}
+	rows, columns, err := newRowReader(input.Reader, header)
+	if err != nil {
+		return err
+	}
 
-	pipeline := d.redisClient.Pipeline()
-	batchSize := 1000
-	count := 0
+	if opts.DryRun {
+		return d.importRows(ctx, input, rows, columns, mode, nil, job, onBatch)
+	}
 
-	for {
-		line, err := reader.ReadString('\n')
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return fmt.Errorf("error reading line: %w", err)
+	// Write into a fresh version, searches keep using the active one until
+	// this import completes
+	version, err := d.versions.begin(ctx, mode, job.ID)
+	if version != nil {
+		job.Version = version.Version
+	}
+	if err != nil {
+		if version != nil {
+			d.versions.fail(context.WithoutCancel(ctx), version, err)
 		}
+		return err
+	}
 
-		fields := strings.Split(strings.TrimSpace(line), "\t")
-		if len(fields) < 5 {
-			continue
+	if err := d.importRows(ctx, input, rows, columns, mode, version, job, onBatch); err != nil {
+		if failErr := d.versions.fail(context.WithoutCancel(ctx), version, err); failErr != nil {
+			log.Printf("dataset version %d: %v", version.Version, failErr)
 		}
+		return err
+	}
 
-		cognate := model.Cognate{
-			ConceptID: fields[0],
-			Lang1:     fields[1],
-			Word1:     fields[2],
-			Lang2:     fields[3],
-			Word2:     fields[4],
+	if err := d.versions.complete(ctx, version); err != nil {
+		if failErr := d.versions.fail(context.WithoutCancel(ctx), version, err); failErr != nil {
+			log.Printf("dataset version %d: %v", version.Version, failErr)
 		}
+		return err
+	}
 
-		if len(fields) > 5 {
-			cognate.Translit1 = fields[5]
-		}
-		if len(fields) > 6 {
-			cognate.Translit2 = fields[6]
-		}
+	// Store import metadata
+	metadata := map[string]interface{}{
+		"total_records": job.RowsWritten,
+		"duplicates":    job.RowsDuplicate,
+		"mode":          mode,
+		"version":       version.Version,
+		"status":        "completed",
+		"timestamp":     time.Now().Unix(),
+	}
 
-		// Convert to JSON for storage
-		jsonData, err := json.Marshal(cognate)
-		if err != nil {
-			return fmt.Errorf("failed to marshal cognate: %w", err)
-		}
+	if err := d.store.SaveMetadata(ctx, "cognates", metadata); err != nil {
+		return err
+	}
 
-		// 1. Store complete cognate data
-		pipeline.RPush(ctx, fmt.Sprintf("concept:%s", cognate.ConceptID), jsonData)
+	return nil
+}
 
-		// 2. Create prefix indices for both words
-		wordInfo1 := fmt.Sprintf("%s|%s", cognate.ConceptID, cognate.Lang1)
-		wordInfo2 := fmt.Sprintf("%s|%s", cognate.ConceptID, cognate.Lang2)
+// importRows writes the cognate rows read from input into a dataset version.
+// A nil version only validates the rows.
+func (d *dataImporter) importRows(ctx context.Context, input *importInput, rows rowReader, columns columnLayout, mode ImportMode, version *model.DatasetVersion, job *model.ImportJob, onBatch func()) error {
+	batchSize := importBatchSize
+	batch := make([]model.Cognate, 0, batchSize)
+	lines := make([]int64, 0, batchSize)
+	seen := make(pairSet)
+
+	flush := func() error {
+		if version == nil {
+			// Dry run: only duplicates within the file can be detected
+			for i, cognate := range batch {
+				if seen.add(cognate) {
+					job.RowsWritten++
+				} else {
+					rejectRow(job, model.RejectDuplicate, lines[i])
+				}
+			}
+		} else {
+			created, err := d.writeCognates(ctx, version.Version, batch, mode)
+			if err != nil {
+				return err
+			}
+
+			for i := range batch {
+				switch {
+				case created[i]:
+					job.RowsWritten++
+					version.Records++
+				case mode == ImportModeUpsert:
+					// Updating an existing pair rewrites its data
+					job.RowsWritten++
+				default:
+					rejectRow(job, model.RejectDuplicate, lines[i])
+				}
+			}
 
-		// Generate and store prefixes for Word1
-		for _, prefix := range generatePrefixes(cognate.Word1) {
-			prefixKey := fmt.Sprintf("prefix:%s", prefix)
-			pipeline.SAdd(ctx, prefixKey, fmt.Sprintf("%s|%s|%s", cognate.Word1, cognate.Lang1, cognate.ConceptID))
+			if err := d.versions.save(ctx, version); err != nil {
+				return err
+			}
 		}
+		batch = batch[:0]
+		lines = lines[:0]
 
-		// Generate and store prefixes for Word2
-		for _, prefix := range generatePrefixes(cognate.Word2) {
-			prefixKey := fmt.Sprintf("prefix:%s", prefix)
-			pipeline.SAdd(ctx, prefixKey, fmt.Sprintf("%s|%s|%s", cognate.Word2, cognate.Lang2, cognate.ConceptID))
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if onBatch != nil {
+			onBatch()
 		}
+		return nil
+	}
 
-		// 3. Store full word indices (for exact matches)
-		pipeline.SAdd(ctx, fmt.Sprintf("word:%s", cognate.Word1), wordInfo1)
-		pipeline.SAdd(ctx, fmt.Sprintf("word:%s", cognate.Word2), wordInfo2)
+	for {
+		fields, lineNumber, err := rows.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil && !errors.Is(err, errMalformedRow) {
+			return err
+		}
+		job.BytesProcessed = input.Progress.count
+		job.RowsRead++
 
-		count++
+		cognate, reason := model.Cognate{}, model.RejectMalformedRow
+		if err == nil {
+			cognate, reason = parseRow(fields, columns)
+		}
+		if reason != "" {
+			rejectRow(job, reason, lineNumber)
+		} else {
+			batch = append(batch, cognate)
+			lines = append(lines, lineNumber)
+		}
 
 		// Execute pipeline in batches
-		if count%batchSize == 0 {
-			if _, err := pipeline.Exec(ctx); err != nil {
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
 				return fmt.Errorf("failed to execute pipeline: %w", err)
 			}
-			pipeline = d.redisClient.Pipeline()
+		} else if job.RowsRead%int64(batchSize) == 0 {
+			// Report progress through long stretches of rejected rows
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if onBatch != nil {
+				onBatch()
+			}
 		}
 	}
 
 	// Execute remaining commands
-	if count%batchSize != 0 {
-		if _, err := pipeline.Exec(ctx); err != nil {
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
 			return fmt.Errorf("failed to execute final pipeline: %w", err)
 		}
 	}
 
-	// Store import metadata
-	metadata := map[string]interface{}{
-		"total_records": count,
-		"status":        "completed",
-		"timestamp":     time.Now().Unix(),
+	return nil
+}
+
+// writeCognates stores a batch of cognates in two steps. The first writes
+// the concept pairs and tells which of them are new. Only those are indexed in
+// the second one, so re-importing a pair never duplicates it or inflates its
+// popularity. It reports for every cognate whether its pair was new.
+func (d *dataImporter) writeCognates(ctx context.Context, version int64, batch []model.Cognate, mode ImportMode) ([]bool, error) {
+	// 1. Store complete cognate data
+	created, err := d.store.PutCognates(ctx, version, batch, mode == ImportModeUpsert)
+	if err != nil {
+		return nil, err
 	}
 
-	metadataJSON, _ := json.Marshal(metadata)
-	if err := d.redisClient.Set(ctx, "import:metadata", metadataJSON, 0).Err(); err != nil {
-		return fmt.Errorf("failed to store metadata: %w", err)
+	entries := make([]store.WordEntry, 0, 2*len(batch))
+	for i, cognate := range batch {
+		if !created[i] {
+			// Existing pairs are already indexed
+			continue
+		}
+
+		// 2. Create prefix and full word indices for both words
+		entries = append(entries,
+			wordEntry(cognate.Word1, cognate.Lang1, cognate.ConceptID),
+			wordEntry(cognate.Word2, cognate.Lang2, cognate.ConceptID),
+		)
 	}
 
-	return nil
+	if err := d.store.IndexWords(ctx, version, entries); err != nil {
+		return nil, err
+	}
+
+	return created, nil
 }
 
-func (d *dataImporter) GetImportStatus() string {
-	return d.status
+func (d *dataImporter) setStatus(status string) {
+	d.statusMu.Lock()
+	defer d.statusMu.Unlock()
+	d.status = status
 }
 
-func (d *dataImporter) ClearDatabase(ctx context.Context) error {
-	return d.redisClient.FlushAll(ctx).Err()
+func (d *dataImporter) GetImportStatus() string {
+	d.statusMu.RLock()
+	defer d.statusMu.RUnlock()
+	return d.status
 }
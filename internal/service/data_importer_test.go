@@ -0,0 +1,219 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"cognet-world-inquiry-service/internal/model"
+	"cognet-world-inquiry-service/internal/store"
+)
+
+const importHeader = "concept_id\tlang1\tword1\tlang2\tword2\ttranslit1\ttranslit2\n"
+
+// importCounts are the counters of a finished import
+type importCounts struct {
+	written, duplicate int64
+}
+
+func newTestImporter() (*dataImporter, store.CognateStore) {
+	cognateStore := store.NewMemoryStore()
+	return NewDataImporter(cognateStore, NewLanguageRegistry(cognateStore)).(*dataImporter), cognateStore
+}
+
+// runImport imports rows below the header and checks the job's counters
+func runImport(t *testing.T, importer *dataImporter, rows string, mode ImportMode, want importCounts) *model.ImportJob {
+	t.Helper()
+
+	job, err := importer.ImportFromReader(context.Background(), strings.NewReader(importHeader+rows), ImportOptions{Mode: mode})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := (importCounts{job.RowsWritten, job.RowsDuplicate}); got != want {
+		t.Errorf("%s import wrote %d and found %d duplicates, want %d and %d", mode, got.written, got.duplicate, want.written, want.duplicate)
+	}
+	return job
+}
+
+// activeCognates returns the cognates of a concept in the active version
+func activeCognates(t *testing.T, cognateStore store.CognateStore, conceptID string) []model.Cognate {
+	t.Helper()
+
+	ctx := context.Background()
+	version, err := cognateStore.ActiveVersion(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cognates, err := cognateStore.GetConcept(ctx, version, conceptID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cognates
+}
+
+// prefixScore returns the index score of a word, which drops with every
+// cognate pair of the word
+func prefixScore(t *testing.T, cognateStore store.CognateStore, lang, word string) float64 {
+	t.Helper()
+
+	ctx := context.Background()
+	version, err := cognateStore.ActiveVersion(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := cognateStore.RangePrefix(ctx, version, lang, strings.ToLower(word), 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.Word == word {
+			return entry.Score
+		}
+	}
+	t.Fatalf("%s:%s is not indexed", lang, word)
+	return 0
+}
+
+// bankRows holds one pair twice, once reversed, and another pair
+const bankRows = "n08420278\teng\tbank\tdeu\tBank\n" +
+	"n08420278\tdeu\tBank\teng\tbank\n" +
+	"n01944390\teng\trun\tdeu\trennen\n"
+
+func TestImportTwiceDoesNotDuplicate(t *testing.T) {
+	tests := []struct {
+		mode   ImportMode
+		first  importCounts
+		second importCounts
+	}{
+		// The reversed pair is a duplicate of the first row
+		{mode: ImportModeAppend, first: importCounts{written: 2, duplicate: 1}, second: importCounts{written: 0, duplicate: 3}},
+		// Upserts rewrite every pair they find again
+		{mode: ImportModeUpsert, first: importCounts{written: 3, duplicate: 0}, second: importCounts{written: 3, duplicate: 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			importer, cognateStore := newTestImporter()
+
+			runImport(t, importer, bankRows, tt.mode, tt.first)
+			score := prefixScore(t, cognateStore, "eng", "bank")
+			runImport(t, importer, bankRows, tt.mode, tt.second)
+
+			if cognates := activeCognates(t, cognateStore, "n08420278"); len(cognates) != 1 {
+				t.Errorf("stored %d bank pairs, want 1", len(cognates))
+			}
+			if cognates := activeCognates(t, cognateStore, "n01944390"); len(cognates) != 1 {
+				t.Errorf("stored %d run pairs, want 1", len(cognates))
+			}
+			if again := prefixScore(t, cognateStore, "eng", "bank"); again != score {
+				t.Errorf("importing again moved bank from score %v to %v", score, again)
+			}
+		})
+	}
+}
+
+func TestImportUpsertOverwrites(t *testing.T) {
+	importer, cognateStore := newTestImporter()
+	runImport(t, importer, "n01944390\trus\tбежать\tukr\tбігти\n", ImportModeAppend, importCounts{written: 1})
+
+	updated := "n01944390\tukr\tбігти\trus\tбежать\tbihty\tbezhat\n"
+	runImport(t, importer, updated, ImportModeAppend, importCounts{duplicate: 1})
+	if cognates := activeCognates(t, cognateStore, "n01944390"); cognates[0].Translit1 != "" {
+		t.Errorf("append changed the stored pair: %+v", cognates[0])
+	}
+
+	runImport(t, importer, updated, ImportModeUpsert, importCounts{written: 1})
+	cognates := activeCognates(t, cognateStore, "n01944390")
+	if len(cognates) != 1 || cognates[0].Translit1 != "bihty" || cognates[0].Translit2 != "bezhat" {
+		t.Errorf("upsert stored %+v", cognates)
+	}
+}
+
+func TestImportReplaceDropsMissingRows(t *testing.T) {
+	importer, cognateStore := newTestImporter()
+	ctx := context.Background()
+
+	first := runImport(t, importer, bankRows, ImportModeAppend, importCounts{written: 2, duplicate: 1})
+	second := runImport(t, importer, "n08420278\teng\tbank\tfra\tbanque\n", ImportModeReplace, importCounts{written: 1})
+
+	if cognates := activeCognates(t, cognateStore, "n01944390"); len(cognates) != 0 {
+		t.Errorf("replaced version kept %d run pairs", len(cognates))
+	}
+	cognates := activeCognates(t, cognateStore, "n08420278")
+	if len(cognates) != 1 || cognates[0].Word2 != "banque" {
+		t.Errorf("replaced version holds %+v, want only bank/banque", cognates)
+	}
+
+	version, err := cognateStore.ActiveVersion(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != second.Version || version == first.Version {
+		t.Fatalf("active version %d, want the replacing import's %d", version, second.Version)
+	}
+	senses, err := cognateStore.WordSenses(ctx, version, "run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(senses) != 0 {
+		t.Errorf("run is still indexed: %v", senses)
+	}
+	langs, err := cognateStore.DatasetLanguages(ctx, version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(langs, ",") != "eng,fra" {
+		t.Errorf("dataset languages = %v, want [eng fra]", langs)
+	}
+
+	// The version replaced is left as it was, for rollbacks
+	if cognates, _ := cognateStore.GetConcept(ctx, first.Version, "n01944390"); len(cognates) != 1 {
+		t.Errorf("previous version lost its run pair")
+	}
+}
+
+func TestImportLanguagesValidates(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{name: "valid", json: `[{"code": "tur", "name": "Turkish", "coordinates": [39, 35]}, {"code": "lat", "name": "Latin"}]`},
+		{name: "bad code", json: `[{"code": "tur"}, {"code": "TR"}]`, wantErr: "index 1"},
+		{name: "latitude out of range", json: `[{"code": "tur", "coordinates": [95, 35]}]`, wantErr: "index 0"},
+		{name: "longitude out of range", json: `[{"code": "tur"}, {"code": "aze"}, {"code": "kaz", "coordinates": [48, 181]}]`, wantErr: "index 2"},
+		{name: "single coordinate", json: `[{"code": "tur", "coordinates": [39]}]`, wantErr: "index 0"},
+		{name: "not json", json: `{"code": "tur"}`, wantErr: "parse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			importer, cognateStore := newTestImporter()
+			ctx := context.Background()
+
+			err := importer.ImportLanguages(ctx, bufio.NewReader(strings.NewReader(tt.json)))
+			languages, listErr := cognateStore.ListLanguages(ctx)
+			if listErr != nil {
+				t.Fatal(listErr)
+			}
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ImportLanguages() error = %v", err)
+				}
+				if len(languages) != 2 {
+					t.Errorf("stored %d languages, want 2", len(languages))
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidLanguage) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ImportLanguages() error = %v, want an invalid language at %q", err, tt.wantErr)
+			}
+			if len(languages) != 0 {
+				t.Errorf("stored %d languages of a rejected file", len(languages))
+			}
+		})
+	}
+}
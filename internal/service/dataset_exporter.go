@@ -0,0 +1,236 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"cognet-world-inquiry-service/internal/model"
+	"cognet-world-inquiry-service/internal/store"
+)
+
+var ErrInvalidExport = errors.New("invalid export")
+
+type DatasetExporter interface {
+	Export(ctx context.Context, w io.Writer, opts ExportOptions) (int64, error)
+}
+
+// ExportFormat is the file format of an export
+type ExportFormat string
+
+const (
+	// ExportFormatTSV uses the fixed import column layout and imports again
+	// as is
+	ExportFormatTSV ExportFormat = "tsv"
+	// ExportFormatCSV has the same columns as TSV
+	ExportFormatCSV ExportFormat = "csv"
+	// ExportFormatJSONL writes one cognate JSON object per line
+	ExportFormatJSONL ExportFormat = "jsonl"
+)
+
+// ParseExportFormat validates a format name, an empty name means tsv. Like
+// ParseImportMode it returns the constants rather than name.
+func ParseExportFormat(name string) (ExportFormat, error) {
+	switch ExportFormat(name) {
+	case "", ExportFormatTSV:
+		return ExportFormatTSV, nil
+	case ExportFormatCSV:
+		return ExportFormatCSV, nil
+	case ExportFormatJSONL:
+		return ExportFormatJSONL, nil
+	default:
+		return "", fmt.Errorf("%w: format %q, expected tsv, jsonl or csv", ErrInvalidExport, name)
+	}
+}
+
+type ExportOptions struct {
+	Format ExportFormat
+	// ConceptPrefix keeps the concepts whose ID starts with it
+	ConceptPrefix string
+	// Langs keeps the pairs involving this language, or exactly this pair of
+	// languages in either order
+	Langs []string
+}
+
+// ValidateExportOptions checks the filters of an export before it starts
+func ValidateExportOptions(opts ExportOptions) error {
+	if len(opts.Langs) > 2 {
+		return fmt.Errorf("%w: at most a pair of languages can be selected", ErrInvalidExport)
+	}
+	for _, lang := range opts.Langs {
+		if !IsLanguageCode(lang) {
+			return fmt.Errorf("%w: language %q is not a 3 letter ISO 639-3 code", ErrInvalidExport, lang)
+		}
+	}
+	return nil
+}
+
+// matches reports whether a cognate passes the language filter
+func (opts ExportOptions) matches(cognate model.Cognate) bool {
+	switch len(opts.Langs) {
+	case 0:
+		return true
+	case 1:
+		return cognate.Lang1 == opts.Langs[0] || cognate.Lang2 == opts.Langs[0]
+	default:
+		a, b := opts.Langs[0], opts.Langs[1]
+		return (cognate.Lang1 == a && cognate.Lang2 == b) || (cognate.Lang1 == b && cognate.Lang2 == a)
+	}
+}
+
+type datasetExporter struct {
+	store store.CognateStore
+}
+
+func NewDatasetExporter(cognateStore store.CognateStore) DatasetExporter {
+	return &datasetExporter{
+		store: cognateStore,
+	}
+}
+
+// Export streams the cognates of the active dataset to w and returns the
+// number of rows written. Concepts are read in batches, so the dataset is
+// never held in memory.
+func (de *datasetExporter) Export(ctx context.Context, w io.Writer, opts ExportOptions) (int64, error) {
+	if opts.Format == "" {
+		opts.Format = ExportFormatTSV
+	}
+	if err := ValidateExportOptions(opts); err != nil {
+		return 0, err
+	}
+
+	version, err := de.store.ActiveVersion(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	out := bufio.NewWriterSize(w, 64*1024)
+	rows, err := newRowWriter(out, opts.Format)
+	if err != nil {
+		return 0, err
+	}
+	if err := rows.header(); err != nil {
+		return 0, err
+	}
+
+	var written int64
+	if version != 0 {
+		err = de.store.ScanConcepts(ctx, version, opts.ConceptPrefix, func(conceptID string, cognates []model.Cognate) error {
+			for _, cognate := range cognates {
+				if !opts.matches(cognate) {
+					continue
+				}
+				if err := rows.write(cognate); err != nil {
+					return err
+				}
+				written++
+			}
+			return ctx.Err()
+		})
+		if err != nil {
+			return written, fmt.Errorf("failed to export cognates: %w", err)
+		}
+	}
+
+	if err := rows.flush(); err != nil {
+		return written, err
+	}
+	return written, out.Flush()
+}
+
+// rowWriter encodes cognates in one export format
+type rowWriter interface {
+	header() error
+	write(cognate model.Cognate) error
+	flush() error
+}
+
+func newRowWriter(out *bufio.Writer, format ExportFormat) (rowWriter, error) {
+	switch format {
+	case ExportFormatTSV:
+		return &tsvRowWriter{out: out}, nil
+	case ExportFormatCSV:
+		return &csvRowWriter{out: csv.NewWriter(out)}, nil
+	case ExportFormatJSONL:
+		return &jsonlRowWriter{out: json.NewEncoder(out)}, nil
+	default:
+		return nil, fmt.Errorf("%w: format %q", ErrInvalidExport, format)
+	}
+}
+
+// exportFields orders the values of a cognate like columnNames
+func exportFields(cognate model.Cognate) []string {
+	fields := make([]string, columnCount)
+	fields[columnConcept] = cognate.ConceptID
+	fields[columnLang1] = cognate.Lang1
+	fields[columnWord1] = cognate.Word1
+	fields[columnLang2] = cognate.Lang2
+	fields[columnWord2] = cognate.Word2
+	fields[columnTranslit1] = cognate.Translit1
+	fields[columnTranslit2] = cognate.Translit2
+	return fields
+}
+
+// tsvEscaper keeps values on one TSV field
+var tsvEscaper = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+
+type tsvRowWriter struct {
+	out *bufio.Writer
+}
+
+func (tw *tsvRowWriter) header() error {
+	_, err := tw.out.WriteString(strings.Join(columnNames[:], "\t") + "\n")
+	return err
+}
+
+func (tw *tsvRowWriter) write(cognate model.Cognate) error {
+	for i, field := range exportFields(cognate) {
+		if i > 0 {
+			tw.out.WriteByte('\t')
+		}
+		tw.out.WriteString(tsvEscaper.Replace(field))
+	}
+	return tw.out.WriteByte('\n')
+}
+
+func (tw *tsvRowWriter) flush() error {
+	return nil
+}
+
+type csvRowWriter struct {
+	out *csv.Writer
+}
+
+func (cw *csvRowWriter) header() error {
+	return cw.out.Write(columnNames[:])
+}
+
+func (cw *csvRowWriter) write(cognate model.Cognate) error {
+	return cw.out.Write(exportFields(cognate))
+}
+
+func (cw *csvRowWriter) flush() error {
+	cw.out.Flush()
+	return cw.out.Error()
+}
+
+type jsonlRowWriter struct {
+	out *json.Encoder
+}
+
+func (jw *jsonlRowWriter) header() error {
+	return nil
+}
+
+func (jw *jsonlRowWriter) write(cognate model.Cognate) error {
+	return jw.out.Encode(cognate)
+}
+
+func (jw *jsonlRowWriter) flush() error {
+	return nil
+}
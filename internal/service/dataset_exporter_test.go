@@ -0,0 +1,126 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"cognet-world-inquiry-service/internal/model"
+	"cognet-world-inquiry-service/internal/store"
+)
+
+// exportCognates holds transliterations and words that CSV has to quote
+var exportCognates = []model.Cognate{
+	{ConceptID: "n00000001", Lang1: "eng", Word1: "bank", Lang2: "deu", Word2: "Bank"},
+	{ConceptID: "n00000001", Lang1: "tur", Word1: "banka", Lang2: "aze", Word2: "bank"},
+	{ConceptID: "n00000001", Lang1: "rus", Word1: "банк", Lang2: "ukr", Word2: "банк", Translit1: "bank", Translit2: "bank"},
+	{ConceptID: "n00000002", Lang1: "aze", Word1: "balıq", Lang2: "tur", Word2: "balık"},
+	{ConceptID: "n00000002", Lang1: "eng", Word1: "fish, raw", Lang2: "tur", Word2: "\"çiğ\" balık"},
+	{ConceptID: "v00000003", Lang1: "tur", Word1: "koşmak", Lang2: "aze", Word2: "qaçmaq"},
+	{ConceptID: "v00000003", Lang1: "eng", Word1: "run", Lang2: "deu", Word2: "rennen"},
+}
+
+// storedCognates returns every cognate of the active version, sorted
+func storedCognates(t *testing.T, cognateStore store.CognateStore) []model.Cognate {
+	t.Helper()
+
+	ctx := context.Background()
+	version, err := cognateStore.ActiveVersion(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cognates := []model.Cognate{}
+	err = cognateStore.ScanConcepts(ctx, version, "", func(conceptID string, concept []model.Cognate) error {
+		cognates = append(cognates, concept...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sortCognates(cognates)
+	return cognates
+}
+
+func sortCognates(cognates []model.Cognate) {
+	sort.Slice(cognates, func(i, j int) bool {
+		if cognates[i].ConceptID != cognates[j].ConceptID {
+			return cognates[i].ConceptID < cognates[j].ConceptID
+		}
+		return cognates[i].PairKey() < cognates[j].PairKey()
+	})
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	_, source := newTestSearch(t, exportCognates)
+	turAze := func(c model.Cognate) bool {
+		return (c.Lang1 == "tur" && c.Lang2 == "aze") || (c.Lang1 == "aze" && c.Lang2 == "tur")
+	}
+	exporter := NewDatasetExporter(source)
+
+	filters := []struct {
+		name string
+		opts ExportOptions
+		keep func(model.Cognate) bool
+	}{
+		{name: "all", keep: func(model.Cognate) bool { return true }},
+		{
+			name: "language",
+			opts: ExportOptions{Langs: []string{"tur"}},
+			keep: func(c model.Cognate) bool { return c.Lang1 == "tur" || c.Lang2 == "tur" },
+		},
+		{
+			name: "language pair",
+			opts: ExportOptions{Langs: []string{"tur", "aze"}},
+			keep: turAze,
+		},
+		{
+			name: "concept prefix",
+			opts: ExportOptions{ConceptPrefix: "v"},
+			keep: func(c model.Cognate) bool { return c.ConceptID == "v00000003" },
+		},
+		{
+			name: "concept prefix and language pair",
+			opts: ExportOptions{ConceptPrefix: "n", Langs: []string{"aze", "tur"}},
+			keep: func(c model.Cognate) bool { return c.ConceptID[0] == 'n' && turAze(c) },
+		},
+	}
+
+	for _, format := range []ExportFormat{ExportFormatTSV, ExportFormatCSV, ExportFormatJSONL} {
+		for _, filter := range filters {
+			t.Run(string(format)+"/"+filter.name, func(t *testing.T) {
+				want := []model.Cognate{}
+				for _, cognate := range exportCognates {
+					if filter.keep(cognate) {
+						want = append(want, cognate)
+					}
+				}
+				sortCognates(want)
+
+				opts := filter.opts
+				opts.Format = format
+				var out bytes.Buffer
+				written, err := exporter.Export(context.Background(), &out, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if written != int64(len(want)) {
+					t.Errorf("exported %d rows, want %d", written, len(want))
+				}
+
+				importer, target := newTestImporter()
+				job, err := importer.ImportFromReader(context.Background(), &out, ImportOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if job.RowsWritten != written || job.RowsSkipped != 0 || job.RowsDuplicate != 0 {
+					t.Errorf("imported %d rows, skipped %d and found %d duplicates, want %d rows", job.RowsWritten, job.RowsSkipped, job.RowsDuplicate, written)
+				}
+				if got := storedCognates(t, target); !reflect.DeepEqual(got, want) {
+					t.Errorf("re-imported %+v, want %+v", got, want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cognet-world-inquiry-service/internal/model"
+	"cognet-world-inquiry-service/internal/store"
+)
+
+// Every import writes into its own dataset version. Searches read the active
+// version, which is only switched once an import has completed.
+
+var (
+	ErrVersionNotFound = errors.New("dataset version not found")
+	ErrVersionNotReady = errors.New("dataset version is not ready")
+	ErrVersionConflict = errors.New("active dataset version changed during the import")
+)
+
+type DatasetVersions interface {
+	ListVersions(ctx context.Context) ([]model.DatasetVersion, error)
+	ActivateVersion(ctx context.Context, version int64) error
+	GarbageCollect(ctx context.Context, keep int) ([]int64, error)
+}
+
+type datasetVersions struct {
+	store store.CognateStore
+}
+
+func NewDatasetVersions(cognateStore store.CognateStore) DatasetVersions {
+	return &datasetVersions{
+		store: cognateStore,
+	}
+}
+
+// begin allocates a new version for an import. Appending imports start from
+// a copy of the active version, replacing imports start empty.
+func (dv *datasetVersions) begin(ctx context.Context, mode ImportMode, jobID string) (*model.DatasetVersion, error) {
+	version, err := dv.store.NextVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &model.DatasetVersion{
+		Version:   version,
+		Status:    model.DatasetVersionBuilding,
+		Mode:      string(mode),
+		JobID:     jobID,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if mode != ImportModeReplace {
+		base, err := dv.store.ActiveVersion(ctx)
+		if err != nil {
+			return nil, err
+		}
+		meta.BaseVersion = base
+	}
+
+	if err := dv.save(ctx, meta); err != nil {
+		return nil, err
+	}
+
+	if meta.BaseVersion > 0 {
+		if err := dv.store.CopyVersion(ctx, meta.BaseVersion, version); err != nil {
+			return meta, fmt.Errorf("failed to copy dataset version %d: %w", meta.BaseVersion, err)
+		}
+		if base, err := dv.store.GetVersion(ctx, meta.BaseVersion); err == nil {
+			meta.Records = base.Records
+		}
+	}
+
+	return meta, nil
+}
+
+// complete marks a version as ready and makes it the active one. A version
+// built on a copy is rejected when the active version is no longer its base,
+// e.g. after a rollback, as activating it would drop those changes.
+func (dv *datasetVersions) complete(ctx context.Context, meta *model.DatasetVersion) error {
+	if meta.Mode != string(ImportModeReplace) {
+		active, err := dv.store.ActiveVersion(ctx)
+		if err != nil {
+			return err
+		}
+		if active != meta.BaseVersion {
+			return fmt.Errorf("%w: built on version %d, active is %d", ErrVersionConflict, meta.BaseVersion, active)
+		}
+	}
+
+	completedAt := time.Now().UTC()
+	meta.Status = model.DatasetVersionReady
+	meta.CompletedAt = &completedAt
+	if err := dv.save(ctx, meta); err != nil {
+		return err
+	}
+
+	return dv.store.SetActiveVersion(ctx, meta.Version)
+}
+
+// fail records why an import did not produce a usable version
+func (dv *datasetVersions) fail(ctx context.Context, meta *model.DatasetVersion, cause error) error {
+	meta.Status = model.DatasetVersionFailed
+	meta.Error = cause.Error()
+	return dv.save(ctx, meta)
+}
+
+func (dv *datasetVersions) save(ctx context.Context, meta *model.DatasetVersion) error {
+	meta.UpdatedAt = time.Now().UTC()
+	return dv.store.SaveVersion(ctx, meta)
+}
+
+// ListVersions returns every known version, newest first
+func (dv *datasetVersions) ListVersions(ctx context.Context) ([]model.DatasetVersion, error) {
+	versions, err := dv.store.ListVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := dv.store.ActiveVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range versions {
+		versions[i].Active = versions[i].Version == active
+	}
+	return versions, nil
+}
+
+// ActivateVersion points searches at a completed version, e.g. to roll back
+func (dv *datasetVersions) ActivateVersion(ctx context.Context, version int64) error {
+	meta, err := dv.store.GetVersion(ctx, version)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrVersionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if meta.Status != model.DatasetVersionReady {
+		return ErrVersionNotReady
+	}
+
+	return dv.store.SetActiveVersion(ctx, version)
+}
+
+// GarbageCollect deletes the data of old versions. The active version and
+// the newest keep ready versions besides it are retained, as is the version
+// of the import holding the import lease. It returns the deleted versions.
+func (dv *datasetVersions) GarbageCollect(ctx context.Context, keep int) ([]int64, error) {
+	versions, err := dv.ListVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	importing, err := dv.store.LeaseOwner(ctx, importLease)
+	if err != nil {
+		return nil, err
+	}
+
+	// Versions are listed newest first, so the ready ones kept are the newest
+	deleted := make([]int64, 0)
+	for _, meta := range versions {
+		switch {
+		case meta.Active:
+			continue
+		case meta.Status == model.DatasetVersionReady && keep > 0:
+			keep--
+			continue
+		case meta.Status == model.DatasetVersionBuilding && importing != "" && meta.JobID == importing:
+			continue
+		}
+
+		if err := dv.store.DeleteVersion(ctx, meta.Version); err != nil {
+			return deleted, fmt.Errorf("failed to delete dataset version %d: %w", meta.Version, err)
+		}
+		deleted = append(deleted, meta.Version)
+	}
+
+	return deleted, nil
+}
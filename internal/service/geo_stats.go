@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"cognet-world-inquiry-service/internal/model"
+)
+
+// earthRadiusKm is the mean radius of the earth
+const earthRadiusKm = 6371.0088
+
+// geoStats computes the spread of the languages with the given codes, each
+// language counts once however many words it has
+func geoStats(codes []string, resolver *languageResolver) *model.GeoStats {
+	unique := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		unique[code] = true
+	}
+	sorted := sortedCodes(unique)
+
+	stats := &model.GeoStats{Languages: len(sorted)}
+	countries := make(map[string]bool)
+	var located []model.LanguageInfo
+	for _, code := range sorted {
+		info := resolver.getLanguageInfo(code)
+		if info.Country != "" {
+			countries[info.Country] = true
+		}
+		if len(info.Coordinates) >= 2 {
+			located = append(located, info)
+		}
+	}
+	stats.Countries = len(countries)
+	stats.LocatedLanguages = len(located)
+	if len(located) == 0 {
+		return stats
+	}
+
+	stats.Centroid = centroid(located)
+	stats.BoundingBox = boundingBox(located)
+
+	for i := range located {
+		for j := i + 1; j < len(located); j++ {
+			distance := greatCircleKm(located[i].Coordinates, located[j].Coordinates)
+			if distance > stats.MaxDistanceKm {
+				stats.MaxDistanceKm = distance
+				stats.FarthestLanguages = []string{located[i].Code, located[j].Code}
+			}
+		}
+	}
+	stats.MaxDistanceKm = math.Round(stats.MaxDistanceKm*10) / 10
+
+	return stats
+}
+
+// centroid averages the coordinates as points on the sphere, so languages on
+// both sides of the antimeridian do not average out to the other side of the
+// world. It is nil when the points cancel out.
+func centroid(languages []model.LanguageInfo) []float64 {
+	var x, y, z float64
+	for _, info := range languages {
+		lat, lng := radians(info.Coordinates[0]), radians(info.Coordinates[1])
+		x += math.Cos(lat) * math.Cos(lng)
+		y += math.Cos(lat) * math.Sin(lng)
+		z += math.Sin(lat)
+	}
+
+	n := float64(len(languages))
+	x, y, z = x/n, y/n, z/n
+	if math.Sqrt(x*x+y*y+z*z) < 1e-9 {
+		return nil
+	}
+
+	lat := math.Atan2(z, math.Sqrt(x*x+y*y))
+	lng := math.Atan2(y, x)
+	return []float64{roundDegrees(degrees(lat)), roundDegrees(degrees(lng))}
+}
+
+// boundingBox spans the latitudes and the shortest range of longitudes,
+// which wraps around the antimeridian when the largest gap between
+// longitudes is not the one across it
+func boundingBox(languages []model.LanguageInfo) *model.BoundingBox {
+	box := &model.BoundingBox{South: 90, North: -90}
+	lngs := make([]float64, 0, len(languages))
+	for _, info := range languages {
+		box.South = math.Min(box.South, info.Coordinates[0])
+		box.North = math.Max(box.North, info.Coordinates[0])
+		lngs = append(lngs, info.Coordinates[1])
+	}
+	sort.Float64s(lngs)
+
+	// The gap across the antimeridian, between the largest and the smallest
+	box.West, box.East = lngs[0], lngs[len(lngs)-1]
+	largestGap := lngs[0] + 360 - lngs[len(lngs)-1]
+	for i := 1; i < len(lngs); i++ {
+		if gap := lngs[i] - lngs[i-1]; gap > largestGap {
+			largestGap = gap
+			box.West, box.East = lngs[i], lngs[i-1]
+		}
+	}
+	return box
+}
+
+// greatCircleKm is the haversine distance between two [lat, long] points
+func greatCircleKm(a, b []float64) float64 {
+	lat1, lat2 := radians(a[0]), radians(b[0])
+	dLat := lat2 - lat1
+	dLng := radians(b[1] - a[1])
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// roundDegrees keeps six decimals, about 10 cm
+func roundDegrees(deg float64) float64 {
+	return math.Round(deg*1e6) / 1e6
+}
+
+// nodeLangs returns the language codes of graph nodes
+func nodeLangs(graph *cognateGraph, keys []string) []string {
+	langs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		langs = append(langs, graph.nodes[key].Lang)
+	}
+	return langs
+}
+
+// FindConceptGeo returns the geographic spread of every cognate of a concept
+func (cs *cognateSearch) FindConceptGeo(ctx context.Context, conceptID string) (*model.ConceptGeoResponse, error) {
+	cognates, err := cs.loadConcept(ctx, conceptID)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := newCognateGraph(cognates)
+	resolver := cs.newLanguageResolver()
+	stats := geoStats(nodeLangs(graph, graph.sortedKeys()), resolver)
+
+	concept, err := cs.conceptInfo(ctx, conceptID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ConceptGeoResponse{
+		ConceptID:        conceptID,
+		Concept:          concept,
+		Stats:            *stats,
+		MissingLanguages: resolver.missingLanguages(),
+	}, nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+
+	"cognet-world-inquiry-service/internal/model"
+	"cognet-world-inquiry-service/internal/store"
+)
+
+// located returns languages at the given [lat, long] points
+func located(points ...[]float64) []model.LanguageInfo {
+	languages := make([]model.LanguageInfo, 0, len(points))
+	for _, point := range points {
+		languages = append(languages, model.LanguageInfo{Coordinates: point})
+	}
+	return languages
+}
+
+func TestGreatCircleKm(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+		want float64
+	}{
+		{name: "same point", a: []float64{51.5, -0.12}, b: []float64{51.5, -0.12}, want: 0},
+		{name: "London to Paris", a: []float64{51.5074, -0.1278}, b: []float64{48.8566, 2.3522}, want: 343.6},
+		{name: "one degree of the equator", a: []float64{0, 10}, b: []float64{0, 11}, want: 111.2},
+		{name: "across the antimeridian", a: []float64{0, 179.5}, b: []float64{0, -179.5}, want: 111.2},
+		{name: "pole to pole", a: []float64{90, 0}, b: []float64{-90, 0}, want: 20015.1},
+		{name: "antipodes", a: []float64{10, 20}, b: []float64{-10, -160}, want: 20015.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := greatCircleKm(tt.a, tt.b); math.Abs(got-tt.want) > 0.1 {
+				t.Errorf("greatCircleKm(%v, %v) = %.1f, want %.1f", tt.a, tt.b, got, tt.want)
+			}
+			if got, back := greatCircleKm(tt.a, tt.b), greatCircleKm(tt.b, tt.a); got != back {
+				t.Errorf("distance %.6f one way and %.6f the other", got, back)
+			}
+		})
+	}
+}
+
+func TestBoundingBox(t *testing.T) {
+	tests := []struct {
+		name      string
+		languages []model.LanguageInfo
+		want      model.BoundingBox
+	}{
+		{
+			name:      "one point",
+			languages: located([]float64{39, 35}),
+			want:      model.BoundingBox{South: 39, West: 35, North: 39, East: 35},
+		},
+		{
+			name:      "europe",
+			languages: located([]float64{51.5, -0.1}, []float64{48.9, 2.4}, []float64{41.9, 12.5}),
+			want:      model.BoundingBox{South: 41.9, West: -0.1, North: 51.5, East: 12.5},
+		},
+		{
+			name:      "across the antimeridian",
+			languages: located([]float64{66, 179}, []float64{64.7, -177.5}, []float64{53, 158.6}),
+			want:      model.BoundingBox{South: 53, West: 158.6, North: 66, East: -177.5},
+		},
+		{
+			name:      "largest gap inside",
+			languages: located([]float64{0, -170}, []float64{0, -10}, []float64{0, 170}),
+			want:      model.BoundingBox{South: 0, West: 170, North: 0, East: -10},
+		},
+		{
+			name:      "largest gap across the antimeridian",
+			languages: located([]float64{0, -120}, []float64{0, 0}, []float64{0, 90}),
+			want:      model.BoundingBox{South: 0, West: -120, North: 0, East: 90},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := boundingBox(tt.languages); *got != tt.want {
+				t.Errorf("boundingBox() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCentroid(t *testing.T) {
+	tests := []struct {
+		name      string
+		languages []model.LanguageInfo
+		want      []float64
+	}{
+		{name: "one point", languages: located([]float64{39, 35}), want: []float64{39, 35}},
+		{name: "on the equator", languages: located([]float64{0, 10}, []float64{0, 30}), want: []float64{0, 20}},
+		{name: "across the antimeridian", languages: located([]float64{0, 170}, []float64{0, -170}), want: []float64{0, 180}},
+		{name: "poles", languages: located([]float64{80, 0}, []float64{80, 180}), want: []float64{90, 0}},
+		{name: "cancelling out", languages: located([]float64{0, 0}, []float64{0, 180})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := centroid(tt.languages)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("centroid() = %v, want nil", got)
+				}
+				return
+			}
+			if len(got) != 2 || greatCircleKm(got, tt.want) > 0.001 {
+				t.Errorf("centroid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeoStats(t *testing.T) {
+	ctx := context.Background()
+	cognateStore := store.NewMemoryStore()
+	if err := cognateStore.SaveLanguages(ctx, []model.LanguageInfo{
+		{Code: "eng", Name: "English", Country: "GB", Coordinates: []float64{51.5074, -0.1278}},
+		{Code: "fra", Name: "French", Country: "FR", Coordinates: []float64{48.8566, 2.3522}},
+		{Code: "oci", Name: "Occitan", Country: "FR", Coordinates: []float64{43.6, 1.44}},
+		{Code: "lat", Name: "Latin"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	languages := NewLanguageRegistry(cognateStore)
+	if err := languages.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	resolver := (&cognateSearch{languages: languages}).newLanguageResolver()
+
+	stats := geoStats([]string{"eng", "fra", "eng", "oci", "lat", "xxx", "fra"}, resolver)
+
+	if stats.Languages != 5 || stats.LocatedLanguages != 3 || stats.Countries != 2 {
+		t.Errorf("languages = %d, located = %d, countries = %d, want 5, 3, 2", stats.Languages, stats.LocatedLanguages, stats.Countries)
+	}
+	if !reflect.DeepEqual(stats.FarthestLanguages, []string{"eng", "oci"}) {
+		t.Errorf("farthest languages = %v, want [eng oci]", stats.FarthestLanguages)
+	}
+	if stats.MaxDistanceKm != 887 {
+		t.Errorf("max distance = %v km, want 887", stats.MaxDistanceKm)
+	}
+	if stats.Centroid == nil || stats.BoundingBox == nil {
+		t.Fatal("located languages have no centroid or bounding box")
+	}
+	if !reflect.DeepEqual(resolver.missingLanguages(), []string{"xxx"}) {
+		t.Errorf("missing languages = %v, want [xxx]", resolver.missingLanguages())
+	}
+
+	if empty := geoStats([]string{"lat"}, resolver); empty.Centroid != nil || empty.BoundingBox != nil || empty.MaxDistanceKm != 0 {
+		t.Errorf("stats without coordinates = %+v", empty)
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"cognet-world-inquiry-service/internal/model"
+)
+
+// ChainsGeoJSON turns cognate chains into a FeatureCollection: a Point for
+// every word with coordinates and a LineString for every pair between two
+// of them. Words keep the coordinates adjusted for the map, the chain
+// index links the features of one chain.
+func ChainsGeoJSON(response *model.CognateChainResponse) *model.FeatureCollection {
+	collection := &model.FeatureCollection{
+		Type:     "FeatureCollection",
+		Features: []model.Feature{},
+	}
+
+	for chainIndex, chain := range response.Chains {
+		positions := make(map[string][]float64, len(chain.Nodes))
+		for _, chainWord := range chain.Nodes {
+			coordinates := chainWord.LanguageInfo.Coordinates
+			if len(coordinates) < 2 {
+				continue
+			}
+			position := []float64{coordinates[1], coordinates[0]}
+			positions[chainWord.ID] = position
+
+			properties := map[string]interface{}{
+				"concept_id": response.ConceptID,
+				"chain":      chainIndex,
+				"word":       chainWord.Word,
+				"translit":   chainWord.Translit1,
+				"lang":       chainWord.LanguageInfo.Code,
+				"language":   chainWord.LanguageInfo.Name,
+				"country":    chainWord.LanguageInfo.Country,
+				"flag":       chainWord.LanguageInfo.Flag,
+			}
+			if original := chainWord.OriginalCoordinates; len(original) >= 2 {
+				// [long, lat] of the language, the point may be moved off it
+				properties["original_coordinates"] = []float64{original[1], original[0]}
+			}
+
+			collection.Features = append(collection.Features, model.Feature{
+				Type: "Feature",
+				ID:   chainWord.ID,
+				Geometry: model.Geometry{
+					Type:        "Point",
+					Coordinates: position,
+				},
+				Properties: properties,
+			})
+		}
+
+		for _, edge := range chain.Edges {
+			from, fromOK := positions[edge.From]
+			to, toOK := positions[edge.To]
+			if !fromOK || !toOK {
+				continue
+			}
+
+			collection.Features = append(collection.Features, model.Feature{
+				Type: "Feature",
+				ID:   edge.From + "|" + edge.To,
+				Geometry: model.Geometry{
+					Type:        "LineString",
+					Coordinates: [][]float64{from, shortWay(from, to)},
+				},
+				Properties: map[string]interface{}{
+					"concept_id": response.ConceptID,
+					"chain":      chainIndex,
+					"from":       edge.From,
+					"to":         edge.To,
+				},
+			})
+		}
+	}
+
+	return collection
+}
+
+// shortWay moves the longitude of to by a full turn when that makes the line
+// from from shorter, so pairs across the antimeridian are not drawn around
+// the world
+func shortWay(from, to []float64) []float64 {
+	switch {
+	case to[0]-from[0] > 180:
+		return []float64{to[0] - 360, to[1]}
+	case to[0]-from[0] < -180:
+		return []float64{to[0] + 360, to[1]}
+	default:
+		return to
+	}
+}
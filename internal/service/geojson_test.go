@@ -0,0 +1,92 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"cognet-world-inquiry-service/internal/model"
+)
+
+// placedWord is a chain word with a marker position and the coordinates of
+// its language
+func placedWord(id string, position, original []float64) model.ChainWord {
+	return model.ChainWord{
+		ID:                  id,
+		Word:                id[4:],
+		LanguageInfo:        model.LanguageInfo{Code: id[:3], Coordinates: position},
+		OriginalCoordinates: original,
+	}
+}
+
+func TestChainsGeoJSON(t *testing.T) {
+	response := &model.CognateChainResponse{
+		ConceptID: "n00001234",
+		Chains: []model.CognateChain{{
+			Nodes: []model.ChainWord{
+				placedWord("tur:balık", []float64{39, 35}, []float64{39, 35}),
+				placedWord("aze:balıq", []float64{40.4, 49.9}, nil),
+				placedWord("xxx:fisk", nil, nil),
+			},
+			Edges: []model.ChainEdge{
+				{From: "tur:balık", To: "aze:balıq"},
+				{From: "aze:balıq", To: "xxx:fisk"},
+			},
+		}, {
+			Nodes: []model.ChainWord{
+				placedWord("ckt:ынныын", []float64{66, 179.9}, []float64{66, 179.9}),
+				placedWord("ess:iqalluk", []float64{66, -179.9}, []float64{66, -179.9}),
+			},
+			Edges: []model.ChainEdge{{From: "ckt:ынныын", To: "ess:iqalluk"}},
+		}},
+	}
+
+	collection := ChainsGeoJSON(response)
+
+	features := make(map[string]model.Feature)
+	for _, feature := range collection.Features {
+		features[feature.ID] = feature
+	}
+
+	tests := []struct {
+		id          string
+		geometry    interface{}
+		original    interface{}
+		notExpected bool
+	}{
+		{id: "tur:balık", geometry: []float64{35, 39}, original: []float64{35, 39}},
+		// Words whose language lost its coordinates still get a point
+		{id: "aze:balıq", geometry: []float64{49.9, 40.4}},
+		{id: "xxx:fisk", notExpected: true},
+		{id: "tur:balık|aze:balıq", geometry: [][]float64{{35, 39}, {49.9, 40.4}}},
+		{id: "aze:balıq|xxx:fisk", notExpected: true},
+		// Lines across the antimeridian take the short way
+		{id: "ckt:ынныын|ess:iqalluk", geometry: [][]float64{{179.9, 66}, {180.1, 66}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			feature, ok := features[tt.id]
+			if tt.notExpected {
+				if ok {
+					t.Fatalf("feature %s was not expected", tt.id)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("feature %s is missing", tt.id)
+			}
+			if !reflect.DeepEqual(feature.Geometry.Coordinates, tt.geometry) {
+				t.Errorf("coordinates = %v, want %v", feature.Geometry.Coordinates, tt.geometry)
+			}
+			if original, ok := feature.Properties["original_coordinates"]; tt.original == nil && ok {
+				t.Errorf("original coordinates = %v, want none", original)
+			} else if tt.original != nil && !reflect.DeepEqual(original, tt.original) {
+				t.Errorf("original coordinates = %v, want %v", original, tt.original)
+			}
+		})
+	}
+
+	if len(collection.Features) != 6 {
+		t.Errorf("got %d features, want 6", len(collection.Features))
+	}
+}
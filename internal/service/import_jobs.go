@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"cognet-world-inquiry-service/internal/model"
+	"cognet-world-inquiry-service/internal/store"
+
+	"github.com/google/uuid"
+)
+
+var ErrImportJobNotFound = errors.New("import job not found")
+
+const (
+	// importJobTTL is how long finished jobs stay queryable
+	importJobTTL = 7 * 24 * time.Hour
+	// importJobSaveInterval throttles progress writes to Redis
+	importJobSaveInterval = 2 * time.Second
+)
+
+// StartImportJob registers a new job and imports source in the background.
+// The job owns source and closes it once the import has finished. Imports
+// that write fail with ErrImportInProgress while another one is running.
+func (d *dataImporter) StartImportJob(ctx context.Context, source io.ReadCloser, size int64, opts ImportOptions) (*model.ImportJob, error) {
+	now := time.Now().UTC()
+	job := &model.ImportJob{
+		ID:         uuid.NewString(),
+		Status:     model.ImportJobQueued,
+		Mode:       string(opts.Mode),
+		DryRun:     opts.DryRun,
+		TotalBytes: size,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	// The job outlives the request that started it
+	jobCtx, leases, err := d.acquireImportLeases(context.Background(), job.ID, !opts.DryRun)
+	if err != nil {
+		source.Close()
+		return nil, err
+	}
+
+	if err := d.saveImportJob(ctx, job); err != nil {
+		leases.release(context.Background())
+		source.Close()
+		return nil, err
+	}
+
+	// The background copy is the only one mutated from now on
+	running := *job
+	go d.runImportJob(jobCtx, leases, &running, source, opts)
+
+	return job, nil
+}
+
+// runImportJob imports in the background. ctx is cancelled when the job
+// loses its leases, bookkeeping still has to be written then.
+func (d *dataImporter) runImportJob(ctx context.Context, leases *importLeases, job *model.ImportJob, source io.ReadCloser, opts ImportOptions) {
+	defer source.Close()
+	defer leases.release(context.WithoutCancel(ctx))
+
+	startedAt := time.Now().UTC()
+	job.Status = model.ImportJobRunning
+	job.StartedAt = &startedAt
+	d.persistImportJob(ctx, job)
+
+	lastSave := time.Now()
+	err := d.importTSV(ctx, source, job.TotalBytes, opts, job, func() {
+		if time.Since(lastSave) >= importJobSaveInterval {
+			d.persistImportJob(ctx, job)
+			lastSave = time.Now()
+		}
+	})
+
+	ctx = context.WithoutCancel(ctx)
+	finishedAt := time.Now().UTC()
+	job.FinishedAt = &finishedAt
+	if err != nil {
+		job.Status = model.ImportJobFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = model.ImportJobCompleted
+	}
+	d.persistImportJob(ctx, job)
+}
+
+// persistImportJob saves progress from the background worker, where there is
+// no caller to hand the error to
+func (d *dataImporter) persistImportJob(ctx context.Context, job *model.ImportJob) {
+	if err := d.saveImportJob(ctx, job); err != nil {
+		log.Printf("import job %s: %v", job.ID, err)
+	}
+}
+
+func (d *dataImporter) saveImportJob(ctx context.Context, job *model.ImportJob) error {
+	job.UpdatedAt = time.Now().UTC()
+	updateImportJobRates(job, job.UpdatedAt)
+
+	return d.store.SaveImportJob(ctx, job, importJobTTL)
+}
+
+// updateImportJobRates derives throughput and the remaining time from the
+// job counters
+func updateImportJobRates(job *model.ImportJob, now time.Time) {
+	job.ETASeconds = nil
+	if job.StartedAt == nil {
+		return
+	}
+
+	end := now
+	if job.FinishedAt != nil {
+		end = *job.FinishedAt
+	}
+	elapsed := end.Sub(*job.StartedAt).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	job.RowsPerSecond = float64(job.RowsRead) / elapsed
+
+	if job.Status != model.ImportJobRunning || job.TotalBytes <= 0 || job.BytesProcessed == 0 {
+		return
+	}
+	bytesPerSecond := float64(job.BytesProcessed) / elapsed
+	remaining := job.TotalBytes - job.BytesProcessed
+	if remaining < 0 {
+		remaining = 0
+	}
+	eta := int64(float64(remaining) / bytesPerSecond)
+	job.ETASeconds = &eta
+}
+
+func (d *dataImporter) GetImportJob(ctx context.Context, id string) (*model.ImportJob, error) {
+	job, err := d.store.GetImportJob(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrImportJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A job whose lease expired was stopped with its replica
+	if job.Status == model.ImportJobRunning || job.Status == model.ImportJobQueued {
+		running, err := d.importRunning(ctx, job.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !running {
+			job.Status = model.ImportJobInterrupted
+			job.ETASeconds = nil
+		}
+	}
+
+	return job, nil
+}
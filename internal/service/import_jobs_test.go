@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"cognet-world-inquiry-service/internal/model"
+)
+
+// waitForJob polls a job until it reaches status
+func waitForJob(t *testing.T, importer *dataImporter, id, status string) *model.ImportJob {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := importer.GetImportJob(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is %s, want %s", id, job.Status, status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestImportJobPersists(t *testing.T) {
+	ctx := context.Background()
+	importer, cognateStore := newTestImporter()
+	input := importHeader + bankRows
+
+	started, err := importer.StartImportJob(ctx, io.NopCloser(strings.NewReader(input)), int64(len(input)), ImportOptions{Mode: ImportModeAppend})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if started.Status != model.ImportJobQueued {
+		t.Errorf("started job is %s, want %s", started.Status, model.ImportJobQueued)
+	}
+	waitForJob(t, importer, started.ID, model.ImportJobCompleted)
+
+	// Another replica reads the job from the store
+	other := NewDataImporter(cognateStore, NewLanguageRegistry(cognateStore)).(*dataImporter)
+	job, err := other.GetImportJob(ctx, started.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.RowsWritten != 2 || job.RowsDuplicate != 1 {
+		t.Errorf("job wrote %d and found %d duplicates, want 2 and 1", job.RowsWritten, job.RowsDuplicate)
+	}
+	if job.BytesProcessed != int64(len(input)) || job.TotalBytes != int64(len(input)) {
+		t.Errorf("job processed %d of %d bytes, want %d", job.BytesProcessed, job.TotalBytes, len(input))
+	}
+	if job.StartedAt == nil || job.FinishedAt == nil || job.ETASeconds != nil {
+		t.Errorf("finished job has started %v, finished %v and eta %v", job.StartedAt, job.FinishedAt, job.ETASeconds)
+	}
+	if owner, _ := cognateStore.LeaseOwner(ctx, importLease); owner != "" {
+		t.Errorf("finished job kept the import lease for %q", owner)
+	}
+
+	if _, err := other.GetImportJob(ctx, "missing"); !errors.Is(err, ErrImportJobNotFound) {
+		t.Errorf("GetImportJob() error = %v, want ErrImportJobNotFound", err)
+	}
+}
+
+func TestImportJobInterrupted(t *testing.T) {
+	ctx := context.Background()
+	importer, cognateStore := newTestImporter()
+
+	// The job blocks on its source until the writer is closed
+	reader, writer := io.Pipe()
+	started, err := importer.StartImportJob(ctx, reader, 0, ImportOptions{Mode: ImportModeAppend})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(writer, importHeader+bankRows); err != nil {
+		t.Fatal(err)
+	}
+	waitForJob(t, importer, started.ID, model.ImportJobRunning)
+
+	// The lease expires when the replica running the job goes away
+	if err := cognateStore.ReleaseLease(ctx, jobLease(started.ID), started.ID); err != nil {
+		t.Fatal(err)
+	}
+	job := waitForJob(t, importer, started.ID, model.ImportJobInterrupted)
+	if job.ETASeconds != nil {
+		t.Errorf("interrupted job has an eta of %d", *job.ETASeconds)
+	}
+
+	// A job saved by a replica that never took the lease is interrupted too
+	stale := &model.ImportJob{ID: "stale", Status: model.ImportJobQueued}
+	if err := importer.saveImportJob(ctx, stale); err != nil {
+		t.Fatal(err)
+	}
+	waitForJob(t, importer, stale.ID, model.ImportJobInterrupted)
+
+	writer.Close()
+	waitForJob(t, importer, started.ID, model.ImportJobCompleted)
+}
+
+func TestUpdateImportJobRates(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(seconds int) *time.Time {
+		t := now.Add(time.Duration(seconds) * time.Second)
+		return &t
+	}
+	eta := func(seconds int64) *int64 { return &seconds }
+
+	tests := []struct {
+		name          string
+		job           model.ImportJob
+		rowsPerSecond float64
+		eta           *int64
+	}{
+		{
+			name: "not started",
+			job:  model.ImportJob{Status: model.ImportJobQueued, RowsRead: 100, TotalBytes: 1000},
+		},
+		{
+			name:          "running",
+			job:           model.ImportJob{Status: model.ImportJobRunning, StartedAt: at(-10), RowsRead: 100, BytesProcessed: 250, TotalBytes: 1000},
+			rowsPerSecond: 10,
+			eta:           eta(30),
+		},
+		{
+			name:          "unknown size",
+			job:           model.ImportJob{Status: model.ImportJobRunning, StartedAt: at(-10), RowsRead: 100, BytesProcessed: 250},
+			rowsPerSecond: 10,
+		},
+		{
+			name:          "nothing read yet",
+			job:           model.ImportJob{Status: model.ImportJobRunning, StartedAt: at(-10), TotalBytes: 1000},
+			rowsPerSecond: 0,
+		},
+		{
+			name:          "read past the size",
+			job:           model.ImportJob{Status: model.ImportJobRunning, StartedAt: at(-10), RowsRead: 100, BytesProcessed: 1200, TotalBytes: 1000},
+			rowsPerSecond: 10,
+			eta:           eta(0),
+		},
+		{
+			name:          "finished",
+			job:           model.ImportJob{Status: model.ImportJobCompleted, StartedAt: at(-30), FinishedAt: at(-20), RowsRead: 100, BytesProcessed: 1000, TotalBytes: 1000},
+			rowsPerSecond: 10,
+		},
+		{
+			name: "started in the future",
+			job:  model.ImportJob{Status: model.ImportJobRunning, StartedAt: at(10), RowsRead: 100, TotalBytes: 1000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := tt.job
+			job.ETASeconds = eta(99)
+			updateImportJobRates(&job, now)
+
+			if job.RowsPerSecond != tt.rowsPerSecond {
+				t.Errorf("rows per second = %v, want %v", job.RowsPerSecond, tt.rowsPerSecond)
+			}
+			switch {
+			case tt.eta == nil && job.ETASeconds != nil:
+				t.Errorf("eta = %d, want none", *job.ETASeconds)
+			case tt.eta != nil && job.ETASeconds == nil:
+				t.Errorf("eta = none, want %d", *tt.eta)
+			case tt.eta != nil && *job.ETASeconds != *tt.eta:
+				t.Errorf("eta = %d, want %d", *job.ETASeconds, *tt.eta)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"cognet-world-inquiry-service/internal/store"
+)
+
+var ErrImportInProgress = errors.New("another import is in progress")
+
+const (
+	// importLease is held by the import writing a dataset version, so only
+	// one import runs at a time and garbage collection leaves its version
+	// alone
+	importLease = "import"
+	// importLeaseTTL is how long a lease outlives a replica that stopped
+	// renewing it
+	importLeaseTTL = 30 * time.Second
+	// importLeaseRenewInterval keeps leases alive independently of progress,
+	// e.g. while a large base version is copied
+	importLeaseRenewInterval = 10 * time.Second
+)
+
+// jobLease is held by every running job, it tells running jobs apart from
+// those whose replica went away
+func jobLease(jobID string) string {
+	return fmt.Sprintf("job:%s", jobID)
+}
+
+// importLeases are the leases of one running job
+type importLeases struct {
+	store  store.CognateStore
+	owner  string
+	names  []string
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// acquireImportLeases takes the job lease and, for imports that write, the
+// import lease. It fails with ErrImportInProgress when another import holds
+// the import lease. The returned context is cancelled when a lease is lost.
+func (d *dataImporter) acquireImportLeases(ctx context.Context, jobID string, write bool) (context.Context, *importLeases, error) {
+	names := []string{jobLease(jobID)}
+	if write {
+		names = append(names, importLease)
+	}
+	return d.acquireLeases(ctx, jobID, names)
+}
+
+// acquireLeases takes the named leases for owner and keeps them alive until
+// release, see acquireImportLeases
+func (d *dataImporter) acquireLeases(ctx context.Context, owner string, names []string) (context.Context, *importLeases, error) {
+	leases := &importLeases{store: d.store, owner: owner, done: make(chan struct{})}
+	for _, name := range names {
+		acquired, err := d.store.AcquireLease(ctx, name, owner, importLeaseTTL)
+		if err == nil && !acquired {
+			err = ErrImportInProgress
+		}
+		if err != nil {
+			leases.releaseAll(ctx)
+			return nil, nil, err
+		}
+		leases.names = append(leases.names, name)
+	}
+
+	leaseCtx, cancel := context.WithCancel(ctx)
+	leases.cancel = cancel
+	go leases.renew(leaseCtx)
+	return leaseCtx, leases, nil
+}
+
+// renew keeps the leases alive until release, cancelling the job's context
+// when one of them was lost
+func (l *importLeases) renew(ctx context.Context) {
+	defer close(l.done)
+
+	ticker := time.NewTicker(importLeaseRenewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, name := range l.names {
+			renewed, err := l.store.RenewLease(ctx, name, l.owner, importLeaseTTL)
+			if err != nil {
+				// Try again on the next tick, the lease outlives a few misses
+				log.Printf("import job %s: %v", l.owner, err)
+				continue
+			}
+			if !renewed {
+				log.Printf("import job %s: lost lease %s, stopping", l.owner, name)
+				l.cancel()
+				return
+			}
+		}
+	}
+}
+
+// release stops renewing and frees the leases
+func (l *importLeases) release(ctx context.Context) {
+	l.cancel()
+	<-l.done
+	l.releaseAll(ctx)
+}
+
+func (l *importLeases) releaseAll(ctx context.Context) {
+	for _, name := range l.names {
+		if err := l.store.ReleaseLease(ctx, name, l.owner); err != nil {
+			log.Printf("import job %s: %v", l.owner, err)
+		}
+	}
+}
+
+// importRunning reports whether the job still holds its lease
+func (d *dataImporter) importRunning(ctx context.Context, jobID string) (bool, error) {
+	owner, err := d.store.LeaseOwner(ctx, jobLease(jobID))
+	if err != nil {
+		return false, err
+	}
+	return owner == jobID, nil
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"cognet-world-inquiry-service/internal/model"
+)
+
+// errMalformedRow marks a row that does not parse, reading goes on with the
+// next one
+var errMalformedRow = errors.New("malformed row")
+
+// rowReader reads the rows of an import one at a time as fields, placed by
+// the column layout of the file. next returns the line of every row in the
+// file, so a header is line 1, and io.EOF after the last row.
+type rowReader interface {
+	next() ([]string, int64, error)
+}
+
+// newRowReader detects the format of an import from its first line: JSON
+// lines start with an object, CSV has a comma separated header and TSV is
+// read otherwise. These are the formats Export writes.
+func newRowReader(reader *bufio.Reader, first string) (rowReader, columnLayout, error) {
+	switch {
+	case strings.HasPrefix(strings.TrimSpace(first), "{"):
+		return &jsonlRows{reader: reader, pending: first}, fixedColumns, nil
+
+	case !strings.Contains(first, "\t") && strings.Contains(first, ","):
+		names, err := csv.NewReader(strings.NewReader(first)).Read()
+		if err != nil {
+			return nil, columnLayout{}, fmt.Errorf("failed to parse csv header: %w", err)
+		}
+		columns, err := columnsFromNames(names)
+		if err != nil {
+			return nil, columns, err
+		}
+		rows := csv.NewReader(reader)
+		rows.FieldsPerRecord = -1
+		return &csvRows{reader: rows}, columns, nil
+
+	default:
+		columns, err := columnsFromHeader(first)
+		if err != nil {
+			return nil, columns, err
+		}
+		return &tsvRows{reader: reader, line: 1}, columns, nil
+	}
+}
+
+type tsvRows struct {
+	reader *bufio.Reader
+	line   int64
+}
+
+func (tr *tsvRows) next() ([]string, int64, error) {
+	line, err := tr.reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, 0, fmt.Errorf("error reading line: %w", err)
+	}
+	// The last line may not end with a newline
+	if err == io.EOF && line == "" {
+		return nil, 0, io.EOF
+	}
+	tr.line++
+	return strings.Split(strings.TrimRight(line, "\r\n"), "\t"), tr.line, nil
+}
+
+type csvRows struct {
+	reader *csv.Reader
+}
+
+func (cr *csvRows) next() ([]string, int64, error) {
+	fields, err := cr.reader.Read()
+	var parseErr *csv.ParseError
+	switch {
+	case errors.As(err, &parseErr):
+		// Lines are counted after the header
+		return nil, int64(parseErr.StartLine) + 1, errMalformedRow
+	case err != nil:
+		return nil, 0, err
+	}
+	line, _ := cr.reader.FieldPos(0)
+	return fields, int64(line) + 1, nil
+}
+
+// jsonlRows reads one cognate object per line, the first line is a row too
+type jsonlRows struct {
+	reader  *bufio.Reader
+	pending string
+	line    int64
+	eof     bool
+}
+
+func (jr *jsonlRows) next() ([]string, int64, error) {
+	line := jr.pending
+	jr.pending = ""
+	if line == "" {
+		if jr.eof {
+			return nil, 0, io.EOF
+		}
+		var err error
+		line, err = jr.reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, 0, fmt.Errorf("error reading line: %w", err)
+		}
+		if err == io.EOF {
+			jr.eof = true
+			if line == "" {
+				return nil, 0, io.EOF
+			}
+		}
+	}
+	jr.line++
+
+	var cognate model.Cognate
+	if err := json.Unmarshal([]byte(line), &cognate); err != nil {
+		return nil, jr.line, errMalformedRow
+	}
+	return exportFields(cognate), jr.line, nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cognet-world-inquiry-service/internal/model"
+)
+
+func TestImportFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		rejected map[string][]int64
+	}{
+		{
+			name: "tsv",
+			input: "concept_id\tlang1\tword1\tlang2\tword2\n" +
+				"n00000001\teng\tbank\tdeu\tBank\n" +
+				"n00000001\teng\tshore\n" +
+				"n00000002\teng\trun\tdeu\trennen\n" +
+				"n00000003\teng\trun\tdeu\t",
+			rejected: map[string][]int64{model.RejectTooFewColumns: {3}, model.RejectEmptyWord: {5}},
+		},
+		{
+			name: "csv with columns by header",
+			input: "word1,lang1,concept_id,lang2,word2\n" +
+				"bank,eng,n00000001,deu,Bank\n" +
+				"\"shore\" line,eng,n00000001,deu,Ufer\n" +
+				"\"fish,\nraw\",eng,n00000002,deu,Fisch\n" +
+				"run,eng,n00000003,deu,\n",
+			rejected: map[string][]int64{model.RejectMalformedRow: {3}, model.RejectEmptyWord: {6}},
+		},
+		{
+			name: "jsonl",
+			input: `{"concept_id":"n00000001","lang1":"eng","word1":"bank","lang2":"deu","word2":"Bank"}` + "\n" +
+				`{"concept_id":"n00000001","lang1":"eng"` + "\n" +
+				`{"concept_id":"n00000002","lang1":"eng","word1":"fish","lang2":"deu","word2":"Fisch","translit1":"fish"}` + "\n" +
+				`{"concept_id":"n00000003","lang1":"en","word1":"run","lang2":"deu","word2":"rennen"}`,
+			rejected: map[string][]int64{model.RejectMalformedRow: {2}, model.RejectInvalidLanguage: {4}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			importer, cognateStore := newTestImporter()
+			job, err := importer.ImportFromReader(context.Background(), strings.NewReader(tt.input), ImportOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if job.RowsRead != 4 || job.RowsWritten != 2 {
+				t.Errorf("read %d rows and wrote %d, want 4 and 2", job.RowsRead, job.RowsWritten)
+			}
+
+			rejected := make(map[string][]int64)
+			for reason, rows := range job.Report.Rejected {
+				rejected[reason] = rows.Lines
+			}
+			if !reflect.DeepEqual(rejected, tt.rejected) {
+				t.Errorf("rejected %v, want %v", rejected, tt.rejected)
+			}
+			if cognates := activeCognates(t, cognateStore, "n00000001"); len(cognates) != 1 || cognates[0].Word2 != "Bank" {
+				t.Errorf("n00000001 holds %+v", cognates)
+			}
+		})
+	}
+}
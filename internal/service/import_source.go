@@ -0,0 +1,185 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+)
+
+// importReadBufferSize is the read buffer of an import
+const importReadBufferSize = 1024 * 1024
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.reader.Read(p)
+	cr.count += int64(n)
+	return n, err
+}
+
+// importInput is the TSV data of an upload, decompressed when needed.
+// Progress counts the bytes consumed of Total, both in the unit progress is
+// best measured in: the upload for plain and gzip files, the entry's
+// uncompressed size for zip archives.
+type importInput struct {
+	Reader   *bufio.Reader
+	Format   string
+	Progress *countingReader
+	Total    int64
+	close    func() error
+}
+
+func (in *importInput) Close() error {
+	if in.close == nil {
+		return nil
+	}
+	return in.close()
+}
+
+// openImportInput detects gzip and zip uploads by their magic bytes, plain
+// TSV is read as is. size is the size of source, 0 when unknown.
+func openImportInput(source io.Reader, size int64) (*importInput, error) {
+	if size <= 0 {
+		if file, ok := source.(interface{ Stat() (os.FileInfo, error) }); ok {
+			if info, err := file.Stat(); err == nil {
+				size = info.Size()
+			}
+		}
+	}
+
+	counter := &countingReader{reader: source}
+	peeker := bufio.NewReaderSize(counter, importReadBufferSize)
+	magic, _ := peeker.Peek(len(zipMagic))
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(peeker)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip file: %w", err)
+		}
+		return &importInput{
+			Reader:   bufio.NewReaderSize(gz, importReadBufferSize),
+			Format:   "gzip",
+			Progress: counter,
+			Total:    size,
+			close:    gz.Close,
+		}, nil
+
+	case bytes.HasPrefix(magic, zipMagic):
+		return openZipInput(source, peeker, size)
+
+	default:
+		return &importInput{
+			Reader:   peeker,
+			Format:   "tsv",
+			Progress: counter,
+			Total:    size,
+		}, nil
+	}
+}
+
+// openZipInput reads the TSV file inside a zip archive. Archives are read
+// from their end, so a source that cannot be read at random is spooled to a
+// temporary file first.
+func openZipInput(source io.Reader, peeked *bufio.Reader, size int64) (*importInput, error) {
+	readerAt, ok := source.(io.ReaderAt)
+	var cleanup func() error
+	if !ok || size <= 0 {
+		tmp, err := os.CreateTemp("", "cognet-import-*.zip")
+		if err != nil {
+			return nil, err
+		}
+		cleanup = func() error {
+			err := tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+
+		// The peeked bytes were already taken from source
+		if size, err = io.Copy(tmp, peeked); err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to spool zip file: %w", err)
+		}
+		readerAt = tmp
+	}
+
+	fail := func(err error) (*importInput, error) {
+		if cleanup != nil {
+			cleanup()
+		}
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		return fail(fmt.Errorf("failed to open zip file: %w", err))
+	}
+
+	entry, err := findTSVEntry(archive)
+	if err != nil {
+		return fail(err)
+	}
+
+	data, err := entry.Open()
+	if err != nil {
+		return fail(fmt.Errorf("failed to open %s in zip file: %w", entry.Name, err))
+	}
+
+	counter := &countingReader{reader: data}
+	return &importInput{
+		Reader:   bufio.NewReaderSize(counter, importReadBufferSize),
+		Format:   "zip",
+		Progress: counter,
+		Total:    int64(entry.UncompressedSize64),
+		close: func() error {
+			err := data.Close()
+			if cleanup != nil {
+				cleanup()
+			}
+			return err
+		},
+	}, nil
+}
+
+// findTSVEntry picks the data file of an archive: its only file, or its only
+// .tsv file
+func findTSVEntry(archive *zip.Reader) (*zip.File, error) {
+	var files, tsvFiles []*zip.File
+	for _, entry := range archive.File {
+		name := entry.Name
+		if entry.FileInfo().IsDir() || strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), ".") {
+			continue
+		}
+		files = append(files, entry)
+		if strings.EqualFold(path.Ext(name), ".tsv") {
+			tsvFiles = append(tsvFiles, entry)
+		}
+	}
+
+	switch {
+	case len(tsvFiles) == 1:
+		return tsvFiles[0], nil
+	case len(tsvFiles) == 0 && len(files) == 1:
+		return files[0], nil
+	case len(files) == 0:
+		return nil, errors.New("zip file is empty")
+	default:
+		return nil, errors.New("zip file must contain exactly one .tsv file")
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"cognet-world-inquiry-service/internal/model"
+)
+
+// maxReportedLines caps the line numbers kept per rejection reason
+const maxReportedLines = 50
+
+// conceptIDPattern matches the WordNet synset IDs CogNet uses: a part of
+// speech letter followed by the 8 digit synset offset, e.g. n00001234
+var conceptIDPattern = regexp.MustCompile(`^[nvasr][0-9]{8}$`)
+
+// Columns of a cognate row
+const (
+	columnConcept = iota
+	columnLang1
+	columnWord1
+	columnLang2
+	columnWord2
+	columnTranslit1
+	columnTranslit2
+	columnCount
+)
+
+// requiredColumns are the columns every row needs, the transliterations are
+// optional
+const requiredColumns = columnWord2 + 1
+
+var columnNames = [columnCount]string{
+	"concept_id", "lang1", "word1", "lang2", "word2", "translit1", "translit2",
+}
+
+// columnAliases maps the normalized header names used by the CogNet
+// releases to their column
+var columnAliases = map[string]int{
+	"conceptid": columnConcept, "concept": columnConcept, "synset": columnConcept,
+	"synsetid": columnConcept, "wordnetid": columnConcept,
+	"lang1": columnLang1, "language1": columnLang1, "l1": columnLang1,
+	"word1": columnWord1, "lemma1": columnWord1, "w1": columnWord1,
+	"lang2": columnLang2, "language2": columnLang2, "l2": columnLang2,
+	"word2": columnWord2, "lemma2": columnWord2, "w2": columnWord2,
+	"translit1": columnTranslit1, "transliteration1": columnTranslit1,
+	"translit2": columnTranslit2, "transliteration2": columnTranslit2,
+}
+
+// columnLayout holds the position of every column in a row, -1 for columns
+// a file does not have
+type columnLayout [columnCount]int
+
+// fixedColumns is the layout of files whose header names no known column:
+// concept_id lang1 word1 lang2 word2 [translit1 translit2]
+var fixedColumns = columnLayout{0, 1, 2, 3, 4, 5, 6}
+
+// normalizeColumnName lowercases a header name and drops everything but
+// letters and digits, so "Lang 1", "lang_1" and "lang1" are the same column
+func normalizeColumnName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// columnsFromHeader finds the columns of a TSV file by the names in its
+// header row, see columnsFromNames
+func columnsFromHeader(header string) (columnLayout, error) {
+	return columnsFromNames(strings.Split(strings.TrimRight(header, "\r\n"), "\t"))
+}
+
+// columnsFromNames finds the columns of a file by the names in its header
+// row. Headers naming no known column fall back to the fixed layout; headers
+// naming only some of the required ones are rejected.
+func columnsFromNames(names []string) (columnLayout, error) {
+	layout := columnLayout{-1, -1, -1, -1, -1, -1, -1}
+	known := 0
+	for i, name := range names {
+		column, ok := columnAliases[normalizeColumnName(name)]
+		if ok && layout[column] < 0 {
+			layout[column] = i
+			known++
+		}
+	}
+
+	if known == 0 {
+		return fixedColumns, nil
+	}
+
+	var missing []string
+	for column := 0; column < requiredColumns; column++ {
+		if layout[column] < 0 {
+			missing = append(missing, columnNames[column])
+		}
+	}
+	if len(missing) > 0 {
+		return layout, fmt.Errorf("header is missing the columns %s", strings.Join(missing, ", "))
+	}
+	return layout, nil
+}
+
+// minFields is the number of fields a row needs to hold every required
+// column
+func (l columnLayout) minFields() int {
+	return max(l[columnConcept], l[columnLang1], l[columnWord1], l[columnLang2], l[columnWord2]) + 1
+}
+
+// field returns the trimmed value of a column, empty when the row is too
+// short or the file has no such column
+func (l columnLayout) field(fields []string, column int) string {
+	index := l[column]
+	if index < 0 || index >= len(fields) {
+		return ""
+	}
+	return strings.TrimSpace(fields[index])
+}
+
+// parseRow turns the fields of a row into a cognate, or tells why the
+// row is rejected
+func parseRow(fields []string, columns columnLayout) (model.Cognate, string) {
+	if len(fields) < columns.minFields() {
+		return model.Cognate{}, model.RejectTooFewColumns
+	}
+
+	cognate := model.Cognate{
+		ConceptID: columns.field(fields, columnConcept),
+		Lang1:     columns.field(fields, columnLang1),
+		Word1:     columns.field(fields, columnWord1),
+		Lang2:     columns.field(fields, columnLang2),
+		Word2:     columns.field(fields, columnWord2),
+		Translit1: columns.field(fields, columnTranslit1),
+		Translit2: columns.field(fields, columnTranslit2),
+	}
+
+	switch {
+	case !conceptIDPattern.MatchString(cognate.ConceptID):
+		return cognate, model.RejectMalformedConceptID
+	case !IsLanguageCode(cognate.Lang1) || !IsLanguageCode(cognate.Lang2):
+		return cognate, model.RejectInvalidLanguage
+	case cognate.Word1 == "" || cognate.Word2 == "":
+		return cognate, model.RejectEmptyWord
+	}
+	return cognate, ""
+}
+
+// rejectRow records a rejected row in the job counters and its report
+func rejectRow(job *model.ImportJob, reason string, line int64) {
+	if reason == model.RejectDuplicate {
+		job.RowsDuplicate++
+	} else {
+		job.RowsSkipped++
+	}
+
+	rejected, ok := job.Report.Rejected[reason]
+	if !ok {
+		rejected = &model.RejectedRows{Lines: []int64{}}
+		job.Report.Rejected[reason] = rejected
+	}
+
+	rejected.Count++
+	if len(rejected.Lines) < maxReportedLines {
+		rejected.Lines = append(rejected.Lines, line)
+	}
+}
+
+// pairSet remembers the cognate pairs seen by a dry run. Pairs are kept as
+// 64 bit hashes to bound memory on large files, so a collision may very
+// rarely report a duplicate that is none.
+type pairSet map[uint64]struct{}
+
+// add reports whether the pair was new
+func (ps pairSet) add(cognate model.Cognate) bool {
+	h := fnv.New64a()
+	h.Write([]byte(cognate.ConceptID))
+	h.Write([]byte{0})
+	h.Write([]byte(cognate.PairKey()))
+	sum := h.Sum64()
+
+	if _, ok := ps[sum]; ok {
+		return false
+	}
+	ps[sum] = struct{}{}
+	return true
+}
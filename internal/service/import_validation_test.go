@@ -0,0 +1,185 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"cognet-world-inquiry-service/internal/model"
+)
+
+func TestParseRow(t *testing.T) {
+	tests := []struct {
+		name   string
+		row    string
+		reason string
+	}{
+		{name: "valid", row: "n00001234\teng\tbank\tdeu\tBank"},
+		{name: "valid with transliterations", row: "n00001234\trus\tбанк\tukr\tбанк\tbank\tbank"},
+		{name: "too few columns", row: "n00001234\teng\tbank\tdeu", reason: model.RejectTooFewColumns},
+		{name: "empty row", row: "", reason: model.RejectTooFewColumns},
+		{name: "concept without part of speech", row: "00001234\teng\tbank\tdeu\tBank", reason: model.RejectMalformedConceptID},
+		{name: "concept with short offset", row: "n0001234\teng\tbank\tdeu\tBank", reason: model.RejectMalformedConceptID},
+		{name: "concept with unknown part of speech", row: "x00001234\teng\tbank\tdeu\tBank", reason: model.RejectMalformedConceptID},
+		{name: "two letter language", row: "n00001234\ten\tbank\tdeu\tBank", reason: model.RejectInvalidLanguage},
+		{name: "uppercase language", row: "n00001234\teng\tbank\tDEU\tBank", reason: model.RejectInvalidLanguage},
+		{name: "empty first word", row: "n00001234\teng\t \tdeu\tBank", reason: model.RejectEmptyWord},
+		{name: "empty second word", row: "n00001234\teng\tbank\tdeu\t", reason: model.RejectEmptyWord},
+		// The concept is checked before the languages and the words
+		{name: "several problems", row: "bad\tEN\t\tdeu\t", reason: model.RejectMalformedConceptID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cognate, reason := parseRow(strings.Split(tt.row, "\t"), fixedColumns)
+			if reason != tt.reason {
+				t.Fatalf("parseRow(%q) rejected for %q, want %q", tt.row, reason, tt.reason)
+			}
+			if reason == "" && (cognate.ConceptID != "n00001234" || cognate.Word1 == "" || cognate.Word2 == "") {
+				t.Errorf("parseRow(%q) = %+v", tt.row, cognate)
+			}
+		})
+	}
+}
+
+func TestParseRowTrims(t *testing.T) {
+	cognate, reason := parseRow(strings.Split(" n00001234 \t eng\tbank \tdeu\tBank\t bank \t", "\t"), fixedColumns)
+	if reason != "" {
+		t.Fatalf("rejected for %q", reason)
+	}
+
+	want := model.Cognate{ConceptID: "n00001234", Lang1: "eng", Word1: "bank", Lang2: "deu", Word2: "Bank", Translit1: "bank"}
+	if cognate != want {
+		t.Errorf("parseRow() = %+v, want %+v", cognate, want)
+	}
+}
+
+func TestRejectRow(t *testing.T) {
+	job := &model.ImportJob{Report: model.ValidationReport{Rejected: make(map[string]*model.RejectedRows)}}
+	for line := int64(1); line <= maxReportedLines+10; line++ {
+		rejectRow(job, model.RejectEmptyWord, line)
+	}
+	rejectRow(job, model.RejectDuplicate, 70)
+	rejectRow(job, model.RejectDuplicate, 71)
+
+	if job.RowsSkipped != maxReportedLines+10 || job.RowsDuplicate != 2 {
+		t.Errorf("skipped %d and duplicate %d, want %d and 2", job.RowsSkipped, job.RowsDuplicate, maxReportedLines+10)
+	}
+
+	empty := job.Report.Rejected[model.RejectEmptyWord]
+	if empty.Count != maxReportedLines+10 {
+		t.Errorf("counted %d empty words, want %d", empty.Count, maxReportedLines+10)
+	}
+	if len(empty.Lines) != maxReportedLines || empty.Lines[0] != 1 || empty.Lines[maxReportedLines-1] != maxReportedLines {
+		t.Errorf("reported lines %v, want the first %d", empty.Lines, maxReportedLines)
+	}
+
+	duplicate := job.Report.Rejected[model.RejectDuplicate]
+	if duplicate.Count != 2 || len(duplicate.Lines) != 2 || duplicate.Lines[1] != 71 {
+		t.Errorf("duplicates = %+v", duplicate)
+	}
+}
+
+func TestPairSet(t *testing.T) {
+	pairs := make(pairSet)
+	bank := model.Cognate{ConceptID: "n00001234", Lang1: "eng", Word1: "bank", Lang2: "deu", Word2: "Bank"}
+
+	if !pairs.add(bank) {
+		t.Error("first pair is not new")
+	}
+	if pairs.add(model.Cognate{ConceptID: "n00001234", Lang1: "deu", Word1: "Bank", Lang2: "eng", Word2: "bank"}) {
+		t.Error("reversed pair is new")
+	}
+	other := bank
+	other.ConceptID = "n00005678"
+	if !pairs.add(other) {
+		t.Error("pair of another concept is not new")
+	}
+}
+
+func TestColumnsFromHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    columnLayout
+		wantErr bool
+	}{
+		{
+			name:   "canonical names",
+			header: "concept_id\tlang1\tword1\tlang2\tword2\ttranslit1\ttranslit2",
+			want:   columnLayout{0, 1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:   "aliases in another order",
+			header: "Lemma 2\tLanguage-2\tSynset ID\tL1\tW1\r\n",
+			want:   columnLayout{2, 3, 4, 1, 0, -1, -1},
+		},
+		{
+			name:   "extra and optional columns",
+			header: "source\tconcept\tlang_1\tword_1\tlang_2\tword_2\ttransliteration2",
+			want:   columnLayout{1, 2, 3, 4, 5, -1, 6},
+		},
+		{
+			name:   "repeated column keeps the first",
+			header: "wordnet_id\tsynset\tlang1\tword1\tlang2\tword2",
+			want:   columnLayout{0, 2, 3, 4, 5, -1, -1},
+		},
+		{
+			name:   "no known names",
+			header: "n00001234\teng\tbank\tdeu\tBank",
+			want:   fixedColumns,
+		},
+		{
+			name:    "missing required columns",
+			header:  "concept_id\tlang1\tword1\ttranslit1",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := columnsFromHeader(tt.header)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("columnsFromHeader(%q) accepted a header without all required columns", tt.header)
+				}
+				if !strings.Contains(err.Error(), "lang2, word2") {
+					t.Errorf("error %q does not name the missing columns", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("columnsFromHeader(%q) error = %v", tt.header, err)
+			}
+			if got != tt.want {
+				t.Errorf("columnsFromHeader(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRowWithHeaderColumns(t *testing.T) {
+	columns, err := columnsFromHeader("word2\tlang2\tconcept_id\tlang1\tword1\tnote")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		row    string
+		reason string
+	}{
+		{row: "Bank\tdeu\tn00001234\teng\tbank\textra"},
+		// Columns after the last required one may be missing
+		{row: "Bank\tdeu\tn00001234\teng\tbank"},
+		{row: "Bank\tdeu\tn00001234\teng", reason: model.RejectTooFewColumns},
+	}
+
+	want := model.Cognate{ConceptID: "n00001234", Lang1: "eng", Word1: "bank", Lang2: "deu", Word2: "Bank"}
+	for _, tt := range tests {
+		cognate, reason := parseRow(strings.Split(tt.row, "\t"), columns)
+		if reason != tt.reason {
+			t.Errorf("parseRow(%q) rejected for %q, want %q", tt.row, reason, tt.reason)
+		} else if reason == "" && cognate != want {
+			t.Errorf("parseRow(%q) = %+v, want %+v", tt.row, cognate, want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cognet-world-inquiry-service/internal/model"
+	"cognet-world-inquiry-service/internal/store"
+)
+
+var (
+	ErrLanguageNotFound = errors.New("language not found")
+	ErrInvalidLanguage  = errors.New("invalid language")
+)
+
+type LanguageCatalog interface {
+	ListLanguages(ctx context.Context) ([]model.LanguageInfo, error)
+	GetLanguage(ctx context.Context, code string) (model.LanguageInfo, error)
+	PutLanguage(ctx context.Context, info model.LanguageInfo) (bool, error)
+	DeleteLanguage(ctx context.Context, code string) error
+	MissingLanguages(ctx context.Context) ([]string, error)
+}
+
+type languageCatalog struct {
+	store     store.CognateStore
+	languages *LanguageRegistry
+}
+
+func NewLanguageCatalog(cognateStore store.CognateStore, languages *LanguageRegistry) LanguageCatalog {
+	return &languageCatalog{
+		store:     cognateStore,
+		languages: languages,
+	}
+}
+
+// IsLanguageCode reports whether code looks like an ISO 639-3 code
+func IsLanguageCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateLanguage checks a language before it is stored. Coordinates are
+// optional, but when given they have to be a [lat, lng] pair on the globe.
+func ValidateLanguage(info model.LanguageInfo) error {
+	if !IsLanguageCode(info.Code) {
+		return fmt.Errorf("%w: code %q is not a 3 letter ISO 639-3 code", ErrInvalidLanguage, info.Code)
+	}
+
+	switch len(info.Coordinates) {
+	case 0:
+	case 2:
+		lat, lng := info.Coordinates[0], info.Coordinates[1]
+		if lat < -90 || lat > 90 {
+			return fmt.Errorf("%w: latitude %g is outside [-90, 90]", ErrInvalidLanguage, lat)
+		}
+		if lng < -180 || lng > 180 {
+			return fmt.Errorf("%w: longitude %g is outside [-180, 180]", ErrInvalidLanguage, lng)
+		}
+	default:
+		return fmt.Errorf("%w: coordinates must be [lat, lng]", ErrInvalidLanguage)
+	}
+
+	return nil
+}
+
+func (lc *languageCatalog) ListLanguages(ctx context.Context) ([]model.LanguageInfo, error) {
+	return lc.store.ListLanguages(ctx)
+}
+
+func (lc *languageCatalog) GetLanguage(ctx context.Context, code string) (model.LanguageInfo, error) {
+	info, err := lc.store.GetLanguage(ctx, code)
+	if errors.Is(err, store.ErrNotFound) {
+		return model.LanguageInfo{}, ErrLanguageNotFound
+	}
+	return info, err
+}
+
+// PutLanguage creates or replaces one language and reports whether it was
+// created
+func (lc *languageCatalog) PutLanguage(ctx context.Context, info model.LanguageInfo) (bool, error) {
+	info.Unknown = false
+	if err := ValidateLanguage(info); err != nil {
+		return false, err
+	}
+
+	_, err := lc.store.GetLanguage(ctx, info.Code)
+	created := errors.Is(err, store.ErrNotFound)
+	if err != nil && !created {
+		return false, err
+	}
+
+	if err := lc.store.SaveLanguages(ctx, []model.LanguageInfo{info}); err != nil {
+		return false, err
+	}
+	if err := lc.languages.Invalidate(ctx); err != nil {
+		return created, fmt.Errorf("failed to refresh languages: %w", err)
+	}
+	return created, nil
+}
+
+func (lc *languageCatalog) DeleteLanguage(ctx context.Context, code string) error {
+	err := lc.store.DeleteLanguage(ctx, code)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrLanguageNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := lc.languages.Invalidate(ctx); err != nil {
+		return fmt.Errorf("failed to refresh languages: %w", err)
+	}
+	return nil
+}
+
+// MissingLanguages lists the codes used in the active dataset that have no
+// language metadata, sorted
+func (lc *languageCatalog) MissingLanguages(ctx context.Context) ([]string, error) {
+	version, err := lc.store.ActiveVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := lc.store.DatasetLanguages(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := make([]string, 0)
+	for _, code := range codes {
+		if _, ok := lc.languages.Lookup(code); !ok {
+			missing = append(missing, code)
+		}
+	}
+	return missing, nil
+}
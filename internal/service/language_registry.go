@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"cognet-world-inquiry-service/internal/model"
+	"cognet-world-inquiry-service/internal/store"
+)
+
+// languageRefreshInterval reloads the registry even without notifications,
+// covering announcements missed while a replica was disconnected
+const languageRefreshInterval = 5 * time.Minute
+
+// LanguageRegistry is an in-process copy of the language metadata, so
+// searches resolve languages without a store round trip per word
+type LanguageRegistry struct {
+	store store.CognateStore
+
+	mu        sync.RWMutex
+	languages map[string]model.LanguageInfo
+}
+
+func NewLanguageRegistry(cognateStore store.CognateStore) *LanguageRegistry {
+	return &LanguageRegistry{
+		store:     cognateStore,
+		languages: make(map[string]model.LanguageInfo),
+	}
+}
+
+// Load replaces the registry with the languages currently in the store
+func (r *LanguageRegistry) Load(ctx context.Context) error {
+	languages, err := r.store.ListLanguages(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load languages: %w", err)
+	}
+
+	byCode := make(map[string]model.LanguageInfo, len(languages))
+	for _, info := range languages {
+		byCode[info.Code] = info
+	}
+
+	r.mu.Lock()
+	r.languages = byCode
+	r.mu.Unlock()
+	return nil
+}
+
+// Lookup returns the metadata of a language code
+func (r *LanguageRegistry) Lookup(code string) (model.LanguageInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	info, ok := r.languages[code]
+	return info, ok
+}
+
+// Invalidate reloads the registry after languages changed and tells the
+// other replicas to do the same
+func (r *LanguageRegistry) Invalidate(ctx context.Context) error {
+	if err := r.Load(ctx); err != nil {
+		return err
+	}
+	return r.store.PublishLanguageChange(ctx)
+}
+
+// Watch keeps the registry up to date until ctx is done, reloading on every
+// change announced by another replica and periodically
+func (r *LanguageRegistry) Watch(ctx context.Context) {
+	changes := r.store.LanguageChanges(ctx)
+	ticker := time.NewTicker(languageRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-changes:
+		case <-ticker.C:
+		}
+
+		if err := r.Load(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("language registry: %v", err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"math"
+	"sort"
+)
+
+// markerSpacingKm is the distance kept between the map markers of two words.
+// It is measured on the ground, so the layout does not depend on the zoom.
+const markerSpacingKm = 30.0
+
+// markerSpacingMinKm is the closest two markers may be
+const markerSpacingMinKm = 0.999 * markerSpacingKm
+
+// placeMarkers gives every word with coordinates a marker position. Words
+// sharing coordinates are spread over rings around them: the first word
+// keeps the center, ring r holds 6r slots markerSpacingKm apart and r times
+// markerSpacingKm from the center, so the rings grow with the number of
+// words. Every word takes the first slot that is clear of all markers placed
+// before it, which also keeps words of nearby languages apart. Words are
+// placed ordered by coordinates and key, so the same words always get the
+// same positions, whatever order they are found in.
+func placeMarkers(origins map[string][]float64) map[string][]float64 {
+	keys := make([]string, 0, len(origins))
+	for key, origin := range origins {
+		if len(origin) >= 2 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := origins[keys[i]], origins[keys[j]]
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		if a[1] != b[1] {
+			return a[1] < b[1]
+		}
+		return keys[i] < keys[j]
+	})
+
+	positions := make(map[string][]float64, len(keys))
+	placed := make(markerGrid)
+	// Markers are never removed, so a slot taken or blocked for one word
+	// stays so for the next word at the same place
+	nextSlot := make(map[[2]float64]int)
+	for _, key := range keys {
+		origin := origins[key]
+		center := [2]float64{origin[0], origin[1]}
+		for slot := nextSlot[center]; ; slot++ {
+			position := ringSlot(origin, slot)
+			if placed.isClear(position) {
+				positions[key] = position
+				placed.add(position)
+				nextSlot[center] = slot + 1
+				break
+			}
+		}
+	}
+	return positions
+}
+
+// markerGrid indexes placed markers by cells of a grid over the earth's
+// volume, markerSpacingKm wide. Markers too close to a position are in its
+// cell or the ones around it, near the poles and the antimeridian too.
+type markerGrid map[[3]int][][]float64
+
+// gridCell returns the cell of a [lat, long] position
+func gridCell(position []float64) [3]int {
+	lat, lng := radians(position[0]), radians(position[1])
+	x := earthRadiusKm * math.Cos(lat) * math.Cos(lng)
+	y := earthRadiusKm * math.Cos(lat) * math.Sin(lng)
+	z := earthRadiusKm * math.Sin(lat)
+	return [3]int{
+		int(math.Floor(x / markerSpacingKm)),
+		int(math.Floor(y / markerSpacingKm)),
+		int(math.Floor(z / markerSpacingKm)),
+	}
+}
+
+func (g markerGrid) add(position []float64) {
+	cell := gridCell(position)
+	g[cell] = append(g[cell], position)
+}
+
+// isClear reports whether a position keeps its distance to every marker.
+// Slots around one center are at least markerSpacingKm apart on the ground,
+// the margin only absorbs rounding.
+func (g markerGrid) isClear(position []float64) bool {
+	cell := gridCell(position)
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			for dz := -1; dz <= 1; dz++ {
+				for _, other := range g[[3]int{cell[0] + dx, cell[1] + dy, cell[2] + dz}] {
+					if greatCircleKm(position, other) < markerSpacingMinKm {
+						return false
+					}
+				}
+			}
+		}
+	}
+	return true
+}
+
+// ringSlot returns the position of a slot around a [lat, long] center, slot
+// 0 being the center itself. Slots of a ring start north and go clockwise,
+// across the poles and the antimeridian where needed.
+func ringSlot(center []float64, slot int) []float64 {
+	if slot == 0 {
+		return []float64{center[0], center[1]}
+	}
+
+	// Ring r starts after the 1 + 6 * (1 + 2 + ... + r-1) slots inside it
+	ring := 1
+	for first := 1; slot >= first+6*ring; ring++ {
+		first += 6 * ring
+	}
+	index := slot - (1 + 3*ring*(ring-1))
+
+	// Walk ring * markerSpacingKm from the center on a great circle, with
+	// the bearing of the slot
+	bearing := 2 * math.Pi * float64(index) / float64(6*ring)
+	angular := float64(ring) * markerSpacingKm / earthRadiusKm
+	lat1, lng1 := radians(center[0]), radians(center[1])
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(angular) + math.Cos(lat1)*math.Sin(angular)*math.Cos(bearing))
+	lng2 := lng1 + math.Atan2(math.Sin(bearing)*math.Sin(angular)*math.Cos(lat1), math.Cos(angular)-math.Sin(lat1)*math.Sin(lat2))
+
+	lat := degrees(lat2)
+	lng := math.Mod(degrees(lng2)+540, 360) - 180
+
+	return []float64{roundDegrees(lat), roundDegrees(lng)}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"cognet-world-inquiry-service/internal/model"
+)
+
+// colocated returns n words of one language at origin, plus the words of
+// the others
+func colocated(n int, origin []float64, others map[string][]float64) map[string][]float64 {
+	origins := make(map[string][]float64, n+len(others))
+	for i := 0; i < n; i++ {
+		origins[fmt.Sprintf("tur:w%04d", i)] = origin
+	}
+	for key, other := range others {
+		origins[key] = other
+	}
+	return origins
+}
+
+func TestPlaceMarkersSpacing(t *testing.T) {
+	tests := []struct {
+		name    string
+		origins map[string][]float64
+	}{
+		{name: "one place", origins: colocated(200, []float64{39, 35}, nil)},
+		{name: "nearby languages", origins: colocated(100, []float64{39, 35}, map[string][]float64{
+			"aze:a": {39.05, 35.05}, "aze:b": {39.05, 35.05}, "aze:c": {39.05, 35.05},
+			"kaz:a": {39.2, 35.1}, "uzb:a": {38.9, 34.8},
+		})},
+		{name: "high latitude", origins: colocated(150, []float64{71, 25}, nil)},
+		{name: "antimeridian", origins: colocated(100, []float64{66, 179.9}, map[string][]float64{
+			"ess:a": {66, -179.95}, "ess:b": {66, -179.95},
+		})},
+		{name: "near the pole", origins: colocated(60, []float64{89.9, 0}, nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			positions := placeMarkers(tt.origins)
+			if len(positions) != len(tt.origins) {
+				t.Fatalf("placed %d markers, want %d", len(positions), len(tt.origins))
+			}
+
+			keys := make([]string, 0, len(positions))
+			for key := range positions {
+				keys = append(keys, key)
+			}
+			for i, a := range keys {
+				for _, b := range keys[i+1:] {
+					if distance := greatCircleKm(positions[a], positions[b]); distance < markerSpacingMinKm {
+						t.Fatalf("%s and %s are %.3f km apart, want at least %.0f km", a, b, distance, markerSpacingKm)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPlaceMarkersKeepsFirstWordAtOrigin(t *testing.T) {
+	positions := placeMarkers(colocated(3, []float64{39, 35}, map[string][]float64{"aze:a": {40.4, 49.9}}))
+
+	if got := positions["tur:w0000"]; !reflect.DeepEqual(got, []float64{39, 35}) {
+		t.Errorf("first word at %v, want the origin", got)
+	}
+	if got := positions["aze:a"]; !reflect.DeepEqual(got, []float64{40.4, 49.9}) {
+		t.Errorf("lone word at %v, want its origin", got)
+	}
+	if _, ok := placeMarkers(map[string][]float64{"xxx:a": nil})["xxx:a"]; ok {
+		t.Error("word without coordinates was placed")
+	}
+}
+
+func TestPlaceMarkersDeterministic(t *testing.T) {
+	origins := colocated(80, []float64{39, 35}, map[string][]float64{
+		"aze:a": {39.05, 35.05}, "aze:b": {39.05, 35.05}, "kaz:a": {39.2, 35.1},
+	})
+	want := placeMarkers(origins)
+
+	// Map iteration order differs between runs, and the positions must not
+	for i := 0; i < 20; i++ {
+		copied := make(map[string][]float64, len(origins))
+		for key, origin := range origins {
+			copied[key] = origin
+		}
+		if got := placeMarkers(copied); !reflect.DeepEqual(got, want) {
+			t.Fatal("positions differ between runs")
+		}
+	}
+}
+
+func TestChainAndPathShareLayout(t *testing.T) {
+	var cognates []model.Cognate
+	for i := 0; i < 30; i++ {
+		cognates = append(cognates, model.Cognate{
+			ConceptID: "n00001234",
+			Lang1:     "tur", Word1: fmt.Sprintf("w%02d", i),
+			Lang2: "aze", Word2: fmt.Sprintf("a%02d", i%4),
+		})
+	}
+
+	ctx := context.Background()
+	var chainPositions map[string][]float64
+	for seed := int64(0); seed < 3; seed++ {
+		shuffled := append([]model.Cognate(nil), cognates...)
+		rand.New(rand.NewSource(seed)).Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+
+		search, cognateStore := newTestSearch(t, shuffled)
+		if err := cognateStore.SaveLanguages(ctx, []model.LanguageInfo{
+			{Code: "tur", Name: "Turkish", Coordinates: []float64{39, 35}},
+			{Code: "aze", Name: "Azerbaijani", Coordinates: []float64{39.05, 35.05}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if err := search.(*cognateSearch).languages.Load(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		chains, err := search.FindCognateChains(ctx, "n00001234", ChainOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		positions := make(map[string][]float64)
+		for _, chain := range chains.Chains {
+			for _, node := range chain.Nodes {
+				positions[node.ID] = node.LanguageInfo.Coordinates
+			}
+		}
+		if chainPositions == nil {
+			chainPositions = positions
+		} else if !reflect.DeepEqual(positions, chainPositions) {
+			t.Fatalf("seed %d: chain positions depend on the storage order", seed)
+		}
+
+		path, err := search.FindPath(ctx, "n00001234", "tur", "w00", "tur", "w04")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(path.Path) != 3 {
+			t.Fatalf("path has %d words, want 3", len(path.Path))
+		}
+		for _, word := range path.Path {
+			if !reflect.DeepEqual(word.LanguageInfo.Coordinates, chainPositions[word.ID]) {
+				t.Errorf("%s is at %v on the path and %v in the chains", word.ID, word.LanguageInfo.Coordinates, chainPositions[word.ID])
+			}
+		}
+	}
+}
+
+func TestMarkersAndWordsShareLanguages(t *testing.T) {
+	ctx := context.Background()
+	cognates := []model.Cognate{
+		{ConceptID: "n00001234", Lang1: "tur", Word1: "balık", Lang2: "aze", Word2: "balıq"},
+	}
+	search, cognateStore := newTestSearch(t, cognates)
+	cs := search.(*cognateSearch)
+	if err := cognateStore.SaveLanguages(ctx, []model.LanguageInfo{
+		{Code: "tur", Name: "Turkish", Coordinates: []float64{39, 35}},
+		{Code: "aze", Name: "Azerbaijani", Coordinates: []float64{40.4, 49.9}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.languages.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	graph := newCognateGraph(cognates)
+	resolver := cs.newLanguageResolver()
+	positions := cs.markerPositions(graph, resolver)
+
+	// The registry reloads between the layout and the words
+	if _, err := cognateStore.ClearLanguages(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.languages.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"tur:balık", "aze:balıq"} {
+		word := cs.chainWord(graph, key, resolver, positions)
+		if word.LanguageInfo.Unknown || len(word.OriginalCoordinates) < 2 {
+			t.Errorf("%s lost its language: %+v", key, word)
+		}
+		if !reflect.DeepEqual(word.LanguageInfo.Coordinates, positions[key]) {
+			t.Errorf("%s is at %v, want its marker at %v", key, word.LanguageInfo.Coordinates, positions[key])
+		}
+	}
+	if missing := resolver.missingLanguages(); missing != nil {
+		t.Errorf("missing languages = %v, want none", missing)
+	}
+}
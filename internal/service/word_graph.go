@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"cognet-world-inquiry-service/internal/model"
+)
+
+// DefaultWordGraphDepth only returns the words paired with the word itself
+const DefaultWordGraphDepth = 1
+
+// FindWordGraph gathers the cognates of a word across every concept it takes
+// part in, found through the word index. The cognates of all those concepts
+// form one graph, words being the same node in every concept, and the words
+// at most depth pairs away from the word are listed under each of their
+// concepts. Concepts are ordered by ID and their words by distance, then
+// language and word.
+func (cs *cognateSearch) FindWordGraph(ctx context.Context, word, lang string, depth int) (*model.WordGraphResponse, error) {
+	if depth <= 0 {
+		depth = DefaultWordGraphDepth
+	}
+
+	version, err := cs.store.ActiveVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	senses, err := cs.store.WordSenses(ctx, version, word)
+	if err != nil {
+		return nil, err
+	}
+
+	conceptIDs := make([]string, 0, len(senses))
+	for _, sense := range senses {
+		if sense.Lang == lang {
+			conceptIDs = append(conceptIDs, sense.ConceptID)
+		}
+	}
+	sort.Strings(conceptIDs)
+
+	var cognates []model.Cognate
+	conceptWords := make(map[string]map[string]bool, len(conceptIDs))
+	for _, conceptID := range conceptIDs {
+		conceptCognates, err := cs.store.GetConcept(ctx, version, conceptID)
+		if err != nil {
+			return nil, err
+		}
+		cognates = append(cognates, conceptCognates...)
+
+		words := make(map[string]bool)
+		for _, cognate := range conceptCognates {
+			words[nodeKey(cognate.Lang1, cognate.Word1)] = true
+			words[nodeKey(cognate.Lang2, cognate.Word2)] = true
+		}
+		conceptWords[conceptID] = words
+	}
+
+	graph := newCognateGraph(cognates)
+	start := nodeKey(lang, word)
+	distances := graph.distances(start)
+
+	resolver := cs.newLanguageResolver()
+	response := &model.WordGraphResponse{
+		Word:           word,
+		LanguageInfo:   resolver.getLanguageInfo(lang),
+		Concepts:       make([]model.WordGraphConcept, 0, len(conceptIDs)),
+		LanguageCounts: make(map[string]int),
+	}
+
+	keys := graph.sortedKeys()
+	seen := make(map[string]bool)
+	for _, conceptID := range conceptIDs {
+		concept := model.WordGraphConcept{
+			ConceptID:      conceptID,
+			Neighbors:      make([]model.WordNeighbor, 0),
+			LanguageCounts: make(map[string]int),
+		}
+		for _, key := range keys {
+			distance, ok := distances[key]
+			if !ok || key == start || distance > depth || !conceptWords[conceptID][key] {
+				continue
+			}
+
+			node := graph.nodes[key]
+			concept.Neighbors = append(concept.Neighbors, model.WordNeighbor{
+				ChainWord: model.ChainWord{
+					ID:           key,
+					Word:         node.Word,
+					Translit1:    node.Translit,
+					LanguageInfo: resolver.getLanguageInfo(node.Lang),
+				},
+				Distance: distance,
+			})
+			concept.LanguageCounts[node.Lang]++
+			if !seen[key] {
+				seen[key] = true
+				response.LanguageCounts[node.Lang]++
+			}
+		}
+		sort.SliceStable(concept.Neighbors, func(i, j int) bool {
+			return concept.Neighbors[i].Distance < concept.Neighbors[j].Distance
+		})
+
+		response.Concepts = append(response.Concepts, concept)
+	}
+	response.MissingLanguages = resolver.missingLanguages()
+
+	concepts, err := cs.store.GetConcepts(ctx, conceptIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range response.Concepts {
+		if info, ok := concepts[response.Concepts[i].ConceptID]; ok {
+			response.Concepts[i].Concept = &info
+		}
+	}
+
+	return response, nil
+}
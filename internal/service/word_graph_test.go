@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"cognet-world-inquiry-service/internal/model"
+)
+
+func TestFindWordGraph(t *testing.T) {
+	search, _ := newTestSearch(t, []model.Cognate{
+		{ConceptID: "n00000001", Lang1: "eng", Word1: "bank", Lang2: "deu", Word2: "bank"},
+		{ConceptID: "n00000001", Lang1: "deu", Word1: "bank", Lang2: "fra", Word2: "banque"},
+		{ConceptID: "n00000002", Lang1: "eng", Word1: "bank", Lang2: "ita", Word2: "banca"},
+		// Only connected to eng:bank through the first concept
+		{ConceptID: "n00000002", Lang1: "fra", Word1: "banque", Lang2: "spa", Word2: "banco"},
+		{ConceptID: "n00000003", Lang1: "deu", Word1: "bank", Lang2: "nld", Word2: "bank"},
+	})
+
+	tests := []struct {
+		depth  int
+		want   []string // per concept, neighbours as lang:word/distance
+		counts map[string]int
+	}{
+		{
+			depth:  1,
+			want:   []string{"n00000001: deu:bank/1", "n00000002: ita:banca/1"},
+			counts: map[string]int{"deu": 1, "ita": 1},
+		},
+		{
+			depth:  2,
+			want:   []string{"n00000001: deu:bank/1 fra:banque/2", "n00000002: ita:banca/1 fra:banque/2"},
+			counts: map[string]int{"deu": 1, "fra": 1, "ita": 1},
+		},
+		{
+			depth:  3,
+			want:   []string{"n00000001: deu:bank/1 fra:banque/2", "n00000002: ita:banca/1 fra:banque/2 spa:banco/3"},
+			counts: map[string]int{"deu": 1, "fra": 1, "ita": 1, "spa": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("depth %d", tt.depth), func(t *testing.T) {
+			response, err := search.FindWordGraph(context.Background(), "bank", "eng", tt.depth)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, concept := range response.Concepts {
+				neighbors := make([]string, 0, len(concept.Neighbors))
+				for _, neighbor := range concept.Neighbors {
+					neighbors = append(neighbors, fmt.Sprintf("%s/%d", neighbor.ID, neighbor.Distance))
+				}
+				got = append(got, concept.ConceptID+": "+strings.Join(neighbors, " "))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("concepts =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+
+			if fmt.Sprint(response.LanguageCounts) != fmt.Sprint(tt.counts) {
+				t.Errorf("language counts = %v, want %v", response.LanguageCounts, tt.counts)
+			}
+		})
+	}
+}
@@ -0,0 +1,1209 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"cognet-world-inquiry-service/internal/model"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// The embedded store keeps the same layout as the Redis one in bbolt
+// buckets. Every dataset version is a top level bucket v<N> holding:
+//
+//	concepts  conceptID \x00 pairKey                   -> cognate json
+//	scores    lang \x00 prefix \x00 member             -> score
+//	ranked    lang \x00 prefix \x00 score \x00 member  -> empty
+//	words     word \x00 conceptID \x00 lang            -> empty
+//	langs     lang                                     -> empty
+//
+// ranked orders a prefix index by score and member, so ranges are a cursor
+// seek. The global index uses an empty lang.
+var (
+	datasetBucket     = []byte("dataset")
+	versionsBucket    = []byte("versions")
+	languagesBucket   = []byte("languages")
+	synsetsBucket     = []byte("synsets")
+	textBucket        = []byte("text")
+	metadataBucket    = []byte("metadata")
+	jobsBucket        = []byte("jobs")
+	clearTokensBucket = []byte("clear_tokens")
+	leasesBucket      = []byte("leases")
+
+	conceptsBucket = []byte("concepts")
+	scoresBucket   = []byte("scores")
+	rankedBucket   = []byte("ranked")
+	wordsBucket    = []byte("words")
+	langsBucket    = []byte("langs")
+
+	textDocsBucket    = []byte("docs")
+	textTermsBucket   = []byte("terms")
+	textLengthsBucket = []byte("lengths")
+
+	activeVersionField = []byte("active")
+	nextVersionField   = []byte("next")
+	textConceptsField  = []byte("concepts")
+	textLengthField    = []byte("length")
+)
+
+const keySeparator = "\x00"
+
+type boltStore struct {
+	db *bolt.DB
+}
+
+// expiringValue wraps records that Redis would store with a TTL
+type expiringValue struct {
+	Value     json.RawMessage `json:"value"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+// NewBoltStore opens, or creates, a single file database at path. Only one
+// process can hold it open at a time.
+func NewBoltStore(path string) (CognateStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embedded database %s: %w", path, err)
+	}
+
+	return &boltStore{
+		db: db,
+	}, nil
+}
+
+func versionBucketName(version int64) []byte {
+	return []byte(fmt.Sprintf("v%d", version))
+}
+
+func isVersionBucket(name []byte) bool {
+	if len(name) < 2 || name[0] != 'v' {
+		return false
+	}
+	_, err := strconv.ParseInt(string(name[1:]), 10, 64)
+	return err == nil
+}
+
+func encodeVersion(version int64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(version))
+	return key
+}
+
+func encodeInt(value int64) []byte {
+	return []byte(strconv.FormatInt(value, 10))
+}
+
+func decodeInt(data []byte) int64 {
+	value, _ := strconv.ParseInt(string(data), 10, 64)
+	return value
+}
+
+// encodeScore maps a float64 to 8 bytes that sort like the number
+func encodeScore(score float64) []byte {
+	bits := math.Float64bits(score)
+	if score >= 0 {
+		bits ^= 1 << 63
+	} else {
+		bits = ^bits
+	}
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, bits)
+	return key
+}
+
+func decodeScore(key []byte) float64 {
+	bits := binary.BigEndian.Uint64(key)
+	if bits&(1<<63) != 0 {
+		bits ^= 1 << 63
+	} else {
+		bits = ^bits
+	}
+	return math.Float64frombits(bits)
+}
+
+func joinKey(parts ...string) []byte {
+	return []byte(strings.Join(parts, keySeparator))
+}
+
+func getJSON(bucket *bolt.Bucket, key []byte, v interface{}) (bool, error) {
+	if bucket == nil {
+		return false, nil
+	}
+	data := bucket.Get(key)
+	if data == nil {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+func putJSON(bucket *bolt.Bucket, key []byte, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(key, data)
+}
+
+// versionData returns a sub bucket of a version, creating both for writes
+func versionData(tx *bolt.Tx, version int64, name []byte) (*bolt.Bucket, error) {
+	if !tx.Writable() {
+		root := tx.Bucket(versionBucketName(version))
+		if root == nil {
+			return nil, nil
+		}
+		return root.Bucket(name), nil
+	}
+
+	root, err := tx.CreateBucketIfNotExists(versionBucketName(version))
+	if err != nil {
+		return nil, err
+	}
+	return root.CreateBucketIfNotExists(name)
+}
+
+func (s *boltStore) NextVersion(ctx context.Context) (int64, error) {
+	var version int64
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(datasetBucket)
+		if err != nil {
+			return err
+		}
+		version = decodeInt(bucket.Get(nextVersionField)) + 1
+		return bucket.Put(nextVersionField, encodeInt(version))
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate dataset version: %w", err)
+	}
+	return version, nil
+}
+
+func (s *boltStore) ActiveVersion(ctx context.Context) (int64, error) {
+	var version int64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket(datasetBucket); bucket != nil {
+			version = decodeInt(bucket.Get(activeVersionField))
+		}
+		return nil
+	})
+	return version, err
+}
+
+func (s *boltStore) SetActiveVersion(ctx context.Context, version int64) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(datasetBucket)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(activeVersionField, encodeInt(version))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to activate dataset version: %w", err)
+	}
+	return nil
+}
+
+func (s *boltStore) SaveVersion(ctx context.Context, meta *model.DatasetVersion) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(versionsBucket)
+		if err != nil {
+			return err
+		}
+		return putJSON(bucket, encodeVersion(meta.Version), meta)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store dataset version: %w", err)
+	}
+	return nil
+}
+
+func (s *boltStore) GetVersion(ctx context.Context, version int64) (*model.DatasetVersion, error) {
+	var meta model.DatasetVersion
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		found, err = getJSON(tx.Bucket(versionsBucket), encodeVersion(version), &meta)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dataset version: %w", err)
+	}
+	if !found {
+		return nil, ErrNotFound
+	}
+	return &meta, nil
+}
+
+func (s *boltStore) ListVersions(ctx context.Context) ([]model.DatasetVersion, error) {
+	versions := make([]model.DatasetVersion, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(versionsBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		// Keys are big endian, walking backwards lists the newest first
+		c := bucket.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var meta model.DatasetVersion
+			if err := json.Unmarshal(v, &meta); err != nil {
+				return err
+			}
+			versions = append(versions, meta)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dataset versions: %w", err)
+	}
+	return versions, nil
+}
+
+// boltBatchSize bounds the keys one transaction copies or scans, so long
+// walks neither hold a read transaction that keeps the file from reusing
+// freed pages nor build one huge write transaction
+const boltBatchSize = 10000
+
+func copyBucket(source, target *bolt.Bucket) error {
+	return source.ForEach(func(k, v []byte) error {
+		// Index entries have empty values, only a sub bucket tells them apart
+		nestedSource := source.Bucket(k)
+		if nestedSource == nil {
+			return target.Put(k, v)
+		}
+
+		nested, err := target.CreateBucketIfNotExists(k)
+		if err != nil {
+			return err
+		}
+		return copyBucket(nestedSource, nested)
+	})
+}
+
+// copyBatch copies up to boltBatchSize keys of one data bucket of a version
+// that follow after, nil for the first batch. It returns the last key copied,
+// nil once the bucket is done.
+func copyBatch(tx *bolt.Tx, from, to int64, name, after []byte) ([]byte, error) {
+	source, _ := versionData(tx, from, name)
+	if source == nil {
+		return nil, nil
+	}
+	target, err := versionData(tx, to, name)
+	if err != nil {
+		return nil, err
+	}
+
+	c := source.Cursor()
+	k, v := c.First()
+	if after != nil {
+		k, v = c.Seek(after)
+		if bytes.Equal(k, after) {
+			k, v = c.Next()
+		}
+	}
+	for copied := 0; k != nil; k, v = c.Next() {
+		if copied == boltBatchSize {
+			// Keys are only valid within the transaction
+			return bytes.Clone(after), nil
+		}
+		if nested := source.Bucket(k); nested != nil {
+			target, err := target.CreateBucketIfNotExists(k)
+			if err != nil {
+				return nil, err
+			}
+			err = copyBucket(nested, target)
+		} else {
+			err = target.Put(k, v)
+		}
+		if err != nil {
+			return nil, err
+		}
+		after = k
+		copied++
+	}
+	return nil, nil
+}
+
+// CopyVersion copies the data buckets of a version one batch per
+// transaction. The target is not readable before the import that copies it
+// completes, so it does not need to be copied atomically.
+func (s *boltStore) CopyVersion(ctx context.Context, from, to int64) error {
+	var names [][]byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		source := tx.Bucket(versionBucketName(from))
+		if source == nil {
+			return nil
+		}
+		// Version buckets only hold data buckets
+		return source.ForEach(func(k, v []byte) error {
+			names = append(names, bytes.Clone(k))
+			return nil
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy dataset version: %w", err)
+	}
+
+	for _, name := range names {
+		var after []byte
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			err := s.db.Update(func(tx *bolt.Tx) error {
+				var err error
+				after, err = copyBatch(tx, from, to, name, after)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to copy dataset version: %w", err)
+			}
+			if after == nil {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (s *boltStore) DeleteVersion(ctx context.Context, version int64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(versionBucketName(version)); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		if bucket := tx.Bucket(versionsBucket); bucket != nil {
+			return bucket.Delete(encodeVersion(version))
+		}
+		return nil
+	})
+}
+
+func (s *boltStore) PutCognates(ctx context.Context, version int64, cognates []model.Cognate, overwrite bool) ([]bool, error) {
+	created := make([]bool, len(cognates))
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := versionData(tx, version, conceptsBucket)
+		if err != nil {
+			return err
+		}
+
+		for i, cognate := range cognates {
+			key := joinKey(cognate.ConceptID, cognate.PairKey())
+			exists := bucket.Get(key) != nil
+			created[i] = !exists
+			if exists && !overwrite {
+				continue
+			}
+
+			if err := putJSON(bucket, key, cognate); err != nil {
+				return fmt.Errorf("failed to store cognate: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *boltStore) GetConcept(ctx context.Context, version int64, conceptID string) ([]model.Cognate, error) {
+	cognates := make([]model.Cognate, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket, _ := versionData(tx, version, conceptsBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		// Keys sort by pair key within a concept
+		prefix := joinKey(conceptID, "")
+		c := bucket.Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var cognate model.Cognate
+			if err := json.Unmarshal(v, &cognate); err != nil {
+				return fmt.Errorf("failed to unmarshal cognate: %w", err)
+			}
+			cognates = append(cognates, cognate)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cognates: %w", err)
+	}
+	return cognates, nil
+}
+
+// scannedConcept is a concept read by one batch of ScanConcepts
+type scannedConcept struct {
+	id       string
+	cognates []model.Cognate
+}
+
+// scanBatch reads the whole concepts whose keys start with prefix, from the
+// key start on, until they hold at least boltBatchSize cognates. It returns
+// the key to continue from, nil once the scan is done.
+func scanBatch(tx *bolt.Tx, version int64, prefix, start []byte) ([]scannedConcept, []byte, error) {
+	bucket, _ := versionData(tx, version, conceptsBucket)
+	if bucket == nil {
+		return nil, nil, nil
+	}
+
+	var concepts []scannedConcept
+	read := 0
+	c := bucket.Cursor()
+	for k, v := c.Seek(start); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		id, _, _ := bytes.Cut(k, []byte(keySeparator))
+		if len(concepts) == 0 || concepts[len(concepts)-1].id != string(id) {
+			if read >= boltBatchSize {
+				// Keys are only valid within the transaction
+				return concepts, bytes.Clone(k), nil
+			}
+			concepts = append(concepts, scannedConcept{id: string(id)})
+		}
+
+		var cognate model.Cognate
+		if err := json.Unmarshal(v, &cognate); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal cognate: %w", err)
+		}
+		last := &concepts[len(concepts)-1]
+		last.cognates = append(last.cognates, cognate)
+		read++
+	}
+	return concepts, nil, nil
+}
+
+// ScanConcepts walks the concepts bucket in key order, reading whole
+// concepts in batches of one read transaction each. fn runs between the
+// transactions, so it may be slow.
+func (s *boltStore) ScanConcepts(ctx context.Context, version int64, conceptPrefix string, fn func(conceptID string, cognates []model.Cognate) error) error {
+	prefix := []byte(conceptPrefix)
+	start := prefix
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var concepts []scannedConcept
+		var next []byte
+		err := s.db.View(func(tx *bolt.Tx) error {
+			var err error
+			concepts, next, err = scanBatch(tx, version, prefix, start)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, concept := range concepts {
+			if err := fn(concept.id, concept.cognates); err != nil {
+				return err
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		start = next
+	}
+}
+
+// addToIndex lowers the score of a member by one, adding it at baseScore
+// first when it is new
+func addToIndex(scores, ranked *bolt.Bucket, lang, prefix, member string, baseScore float64) error {
+	scoreKey := joinKey(lang, prefix, member)
+
+	score := baseScore
+	if current := scores.Get(scoreKey); current != nil {
+		score = decodeScore(current)
+		if err := ranked.Delete(rankedKey(lang, prefix, score, member)); err != nil {
+			return err
+		}
+	}
+	score--
+
+	if err := scores.Put(scoreKey, encodeScore(score)); err != nil {
+		return err
+	}
+	return ranked.Put(rankedKey(lang, prefix, score, member), []byte{})
+}
+
+func rankedKey(lang, prefix string, score float64, member string) []byte {
+	key := joinKey(lang, prefix, "")
+	key = append(key, encodeScore(score)...)
+	key = append(key, keySeparator...)
+	return append(key, member...)
+}
+
+func (s *boltStore) IndexWords(ctx context.Context, version int64, entries []WordEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		scores, err := versionData(tx, version, scoresBucket)
+		if err != nil {
+			return err
+		}
+		ranked, err := versionData(tx, version, rankedBucket)
+		if err != nil {
+			return err
+		}
+		words, err := versionData(tx, version, wordsBucket)
+		if err != nil {
+			return err
+		}
+		langs, err := versionData(tx, version, langsBucket)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			member := PrefixEntry{Word: entry.Word, Lang: entry.Lang, ConceptID: entry.ConceptID}.Member()
+			for _, prefix := range entry.Prefixes {
+				for _, lang := range []string{"", entry.Lang} {
+					if err := addToIndex(scores, ranked, lang, prefix, member, entry.BaseScore); err != nil {
+						return err
+					}
+				}
+			}
+
+			if err := words.Put(joinKey(entry.Word, entry.ConceptID, entry.Lang), []byte{}); err != nil {
+				return err
+			}
+			if err := langs.Put([]byte(entry.Lang), []byte{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *boltStore) RangePrefix(ctx context.Context, version int64, lang, prefix string, offset, count int64) ([]PrefixEntry, error) {
+	entries := make([]PrefixEntry, 0)
+	if count <= 0 {
+		return entries, nil
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		ranked, _ := versionData(tx, version, rankedBucket)
+		if ranked == nil {
+			return nil
+		}
+
+		keyPrefix := joinKey(lang, prefix, "")
+		c := ranked.Cursor()
+		for k, _ := c.Seek(keyPrefix); k != nil && bytes.HasPrefix(k, keyPrefix); k, _ = c.Next() {
+			if offset > 0 {
+				offset--
+				continue
+			}
+
+			rest := k[len(keyPrefix):]
+			if len(rest) < 9 {
+				continue
+			}
+
+			// member format: "word|language|conceptID"
+			parts := strings.Split(string(rest[9:]), "|")
+			if len(parts) != 3 {
+				continue
+			}
+			entries = append(entries, PrefixEntry{Word: parts[0], Lang: parts[1], ConceptID: parts[2], Score: decodeScore(rest[:8])})
+
+			if int64(len(entries)) == count {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (s *boltStore) WordSenses(ctx context.Context, version int64, word string) ([]WordSense, error) {
+	senses := make([]WordSense, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		words, _ := versionData(tx, version, wordsBucket)
+		if words == nil {
+			return nil
+		}
+
+		prefix := joinKey(word, "")
+		c := words.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			parts := strings.Split(string(k[len(prefix):]), keySeparator)
+			if len(parts) != 2 {
+				continue
+			}
+			senses = append(senses, WordSense{ConceptID: parts[0], Lang: parts[1]})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch word index: %w", err)
+	}
+	return senses, nil
+}
+
+func (s *boltStore) DatasetLanguages(ctx context.Context, version int64) ([]string, error) {
+	codes := make([]string, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		langs, _ := versionData(tx, version, langsBucket)
+		if langs == nil {
+			return nil
+		}
+
+		return langs.ForEach(func(k, v []byte) error {
+			codes = append(codes, string(k))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch dataset languages: %w", err)
+	}
+	return codes, nil
+}
+
+func (s *boltStore) SaveLanguages(ctx context.Context, languages []model.LanguageInfo) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(languagesBucket)
+		if err != nil {
+			return err
+		}
+
+		for _, info := range languages {
+			if err := putJSON(bucket, []byte(info.Code), info); err != nil {
+				return fmt.Errorf("failed to marshal language info: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store languages: %w", err)
+	}
+	return nil
+}
+
+func (s *boltStore) GetLanguage(ctx context.Context, code string) (model.LanguageInfo, error) {
+	var langInfo model.LanguageInfo
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		found, err = getJSON(tx.Bucket(languagesBucket), []byte(code), &langInfo)
+		return err
+	})
+	if err != nil {
+		return model.LanguageInfo{}, fmt.Errorf("failed to get language info: %w", err)
+	}
+	if !found {
+		return model.LanguageInfo{}, ErrNotFound
+	}
+	return langInfo, nil
+}
+
+func (s *boltStore) ListLanguages(ctx context.Context) ([]model.LanguageInfo, error) {
+	languages := make([]model.LanguageInfo, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(languagesBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var langInfo model.LanguageInfo
+			if err := json.Unmarshal(v, &langInfo); err != nil {
+				return err
+			}
+			languages = append(languages, langInfo)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list languages: %w", err)
+	}
+	return languages, nil
+}
+
+func (s *boltStore) DeleteLanguage(ctx context.Context, code string) error {
+	var found bool
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(languagesBucket)
+		if bucket == nil || bucket.Get([]byte(code)) == nil {
+			return nil
+		}
+		found = true
+		return bucket.Delete([]byte(code))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete language info: %w", err)
+	}
+	if !found {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *boltStore) SaveConcepts(ctx context.Context, concepts []model.ConceptInfo) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(synsetsBucket)
+		if err != nil {
+			return err
+		}
+
+		for _, info := range concepts {
+			if err := putJSON(bucket, []byte(info.ConceptID), info); err != nil {
+				return fmt.Errorf("failed to marshal concept info: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store concepts: %w", err)
+	}
+	return nil
+}
+
+func (s *boltStore) GetConcepts(ctx context.Context, conceptIDs []string) (map[string]model.ConceptInfo, error) {
+	concepts := make(map[string]model.ConceptInfo, len(conceptIDs))
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(synsetsBucket)
+		for _, conceptID := range conceptIDs {
+			var info model.ConceptInfo
+			found, err := getJSON(bucket, []byte(conceptID), &info)
+			if err != nil {
+				return err
+			}
+			if found {
+				concepts[conceptID] = info
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get concept info: %w", err)
+	}
+	return concepts, nil
+}
+
+// textData returns a sub-bucket of the full-text index, creating it in
+// writable transactions
+func textData(tx *bolt.Tx, name []byte) (*bolt.Bucket, error) {
+	if !tx.Writable() {
+		text := tx.Bucket(textBucket)
+		if text == nil {
+			return nil, nil
+		}
+		return text.Bucket(name), nil
+	}
+
+	text, err := tx.CreateBucketIfNotExists(textBucket)
+	if err != nil {
+		return nil, err
+	}
+	return text.CreateBucketIfNotExists(name)
+}
+
+// IndexConceptTerms keeps the terms of each concept in docs, postings in
+// terms (term \x00 conceptID -> frequency), concept lengths in lengths and
+// the number of concepts and their total length as counters
+func (s *boltStore) IndexConceptTerms(ctx context.Context, docs []ConceptTerms) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		docsData, err := textData(tx, textDocsBucket)
+		if err != nil {
+			return err
+		}
+		terms, err := textData(tx, textTermsBucket)
+		if err != nil {
+			return err
+		}
+		lengths, err := textData(tx, textLengthsBucket)
+		if err != nil {
+			return err
+		}
+		text := tx.Bucket(textBucket)
+		concepts := decodeInt(text.Get(textConceptsField))
+		totalLength := decodeInt(text.Get(textLengthField))
+
+		for _, doc := range docs {
+			var old map[string]int
+			found, err := getJSON(docsData, []byte(doc.ConceptID), &old)
+			if err != nil {
+				return err
+			}
+			if found {
+				for term := range old {
+					if err := terms.Delete(joinKey(term, doc.ConceptID)); err != nil {
+						return err
+					}
+				}
+				concepts--
+				totalLength -= int64(termCount(old))
+				if err := docsData.Delete([]byte(doc.ConceptID)); err != nil {
+					return err
+				}
+				if err := lengths.Delete([]byte(doc.ConceptID)); err != nil {
+					return err
+				}
+			}
+
+			if len(doc.Terms) == 0 {
+				continue
+			}
+			for term, frequency := range doc.Terms {
+				if err := terms.Put(joinKey(term, doc.ConceptID), encodeInt(int64(frequency))); err != nil {
+					return err
+				}
+			}
+			if err := putJSON(docsData, []byte(doc.ConceptID), doc.Terms); err != nil {
+				return err
+			}
+			length := int64(termCount(doc.Terms))
+			if err := lengths.Put([]byte(doc.ConceptID), encodeInt(length)); err != nil {
+				return err
+			}
+			concepts++
+			totalLength += length
+		}
+
+		if err := text.Put(textConceptsField, encodeInt(concepts)); err != nil {
+			return err
+		}
+		return text.Put(textLengthField, encodeInt(totalLength))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index concept terms: %w", err)
+	}
+	return nil
+}
+
+func (s *boltStore) MatchConceptTerms(ctx context.Context, terms []string) (*TermMatches, error) {
+	matches := &TermMatches{
+		Postings: make(map[string]map[string]int, len(terms)),
+		Lengths:  make(map[string]int),
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		termsData, _ := textData(tx, textTermsBucket)
+		lengths, _ := textData(tx, textLengthsBucket)
+		for _, term := range terms {
+			concepts := make(map[string]int)
+			matches.Postings[term] = concepts
+			if termsData == nil {
+				continue
+			}
+
+			prefix := joinKey(term, "")
+			c := termsData.Cursor()
+			for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+				conceptID := string(k[len(prefix):])
+				concepts[conceptID] = int(decodeInt(v))
+				matches.Lengths[conceptID] = int(decodeInt(lengths.Get([]byte(conceptID))))
+			}
+		}
+
+		if text := tx.Bucket(textBucket); text != nil {
+			matches.Concepts = decodeInt(text.Get(textConceptsField))
+			matches.TotalLength = decodeInt(text.Get(textLengthField))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to match terms: %w", err)
+	}
+	return matches, nil
+}
+
+// PublishLanguageChange has nobody to notify, the database file can only be
+// opened by one process
+func (s *boltStore) PublishLanguageChange(ctx context.Context) error {
+	return nil
+}
+
+func (s *boltStore) LanguageChanges(ctx context.Context) <-chan struct{} {
+	return nil
+}
+
+func (s *boltStore) SaveMetadata(ctx context.Context, name string, metadata map[string]interface{}) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(metadataBucket)
+		if err != nil {
+			return err
+		}
+		return putJSON(bucket, []byte(name), metadata)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store metadata: %w", err)
+	}
+	return nil
+}
+
+// purgeExpired deletes the expired records of a bucket of expiringValues.
+// Nothing expires them on its own as Redis does.
+func purgeExpired(bucket *bolt.Bucket) error {
+	now := time.Now()
+	var expired [][]byte
+	err := bucket.ForEach(func(k, v []byte) error {
+		var stored expiringValue
+		if err := json.Unmarshal(v, &stored); err != nil {
+			return err
+		}
+		if now.After(stored.ExpiresAt) {
+			expired = append(expired, bytes.Clone(k))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, k := range expired {
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putExpiring writes a record with a TTL, purging the expired records of
+// the bucket whenever a new one is added
+func (s *boltStore) putExpiring(bucketName []byte, key string, v interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(bucketName)
+		if err != nil {
+			return err
+		}
+		if bucket.Get([]byte(key)) == nil {
+			if err := purgeExpired(bucket); err != nil {
+				return err
+			}
+		}
+		return putJSON(bucket, []byte(key), expiringValue{Value: data, ExpiresAt: time.Now().Add(ttl)})
+	})
+}
+
+// getExpiring reads a record written by putExpiring, expired records are
+// reported as missing and removed when take is set
+func (s *boltStore) getExpiring(bucketName []byte, key string, v interface{}, take bool) (bool, error) {
+	var stored expiringValue
+	var found bool
+	read := func(tx *bolt.Tx) error {
+		var err error
+		found, err = getJSON(tx.Bucket(bucketName), []byte(key), &stored)
+		if err != nil || !found || !take {
+			return err
+		}
+		return tx.Bucket(bucketName).Delete([]byte(key))
+	}
+
+	var err error
+	if take {
+		err = s.db.Update(read)
+	} else {
+		err = s.db.View(read)
+	}
+	if err != nil || !found || time.Now().After(stored.ExpiresAt) {
+		return false, err
+	}
+	return true, json.Unmarshal(stored.Value, v)
+}
+
+func (s *boltStore) SaveImportJob(ctx context.Context, job *model.ImportJob, ttl time.Duration) error {
+	if err := s.putExpiring(jobsBucket, job.ID, job, ttl); err != nil {
+		return fmt.Errorf("failed to store import job: %w", err)
+	}
+	return nil
+}
+
+func (s *boltStore) GetImportJob(ctx context.Context, id string) (*model.ImportJob, error) {
+	var job model.ImportJob
+	found, err := s.getExpiring(jobsBucket, id, &job, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+	if !found {
+		return nil, ErrNotFound
+	}
+	return &job, nil
+}
+
+func (s *boltStore) SaveClearToken(ctx context.Context, token, scope string, ttl time.Duration) error {
+	if err := s.putExpiring(clearTokensBucket, token, scope, ttl); err != nil {
+		return fmt.Errorf("failed to store confirmation token: %w", err)
+	}
+	return nil
+}
+
+func (s *boltStore) TakeClearToken(ctx context.Context, token string) (string, error) {
+	var scope string
+	found, err := s.getExpiring(clearTokensBucket, token, &scope, true)
+	if err != nil {
+		return "", fmt.Errorf("failed to check confirmation token: %w", err)
+	}
+	if !found {
+		return "", ErrNotFound
+	}
+	return scope, nil
+}
+
+// leaseOwner returns the owner of a lease that has not expired
+func leaseOwner(tx *bolt.Tx, name string) (string, error) {
+	var stored expiringValue
+	found, err := getJSON(tx.Bucket(leasesBucket), []byte(name), &stored)
+	if err != nil || !found || time.Now().After(stored.ExpiresAt) {
+		return "", err
+	}
+
+	var owner string
+	return owner, json.Unmarshal(stored.Value, &owner)
+}
+
+// updateLease writes a lease when its current owner, "" for a free lease,
+// is the expected one
+func (s *boltStore) updateLease(name, expected, owner string, ttl time.Duration) (bool, error) {
+	updated := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		current, err := leaseOwner(tx, name)
+		if err != nil || current != expected {
+			return err
+		}
+
+		bucket, err := tx.CreateBucketIfNotExists(leasesBucket)
+		if err != nil {
+			return err
+		}
+		// Leases of replicas that went away are never released
+		if bucket.Get([]byte(name)) == nil {
+			if err := purgeExpired(bucket); err != nil {
+				return err
+			}
+		}
+		value, err := json.Marshal(owner)
+		if err != nil {
+			return err
+		}
+		updated = true
+		return putJSON(bucket, []byte(name), expiringValue{Value: value, ExpiresAt: time.Now().Add(ttl)})
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to update lease %s: %w", name, err)
+	}
+	return updated, nil
+}
+
+func (s *boltStore) AcquireLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	return s.updateLease(name, "", owner, ttl)
+}
+
+func (s *boltStore) RenewLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	return s.updateLease(name, owner, owner, ttl)
+}
+
+func (s *boltStore) ReleaseLease(ctx context.Context, name, owner string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		current, err := leaseOwner(tx, name)
+		if err != nil || current == "" || current != owner {
+			return err
+		}
+		return tx.Bucket(leasesBucket).Delete([]byte(name))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release lease %s: %w", name, err)
+	}
+	return nil
+}
+
+func (s *boltStore) LeaseOwner(ctx context.Context, name string) (string, error) {
+	var owner string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		owner, err = leaseOwner(tx, name)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read lease %s: %w", name, err)
+	}
+	return owner, nil
+}
+
+// deleteBuckets drops whole buckets and the given metadata entries in one
+// transaction, returning how many keys they held
+func (s *boltStore) deleteBuckets(match func(name []byte) bool, metadata string, onProgress func(deleted int64)) (int64, error) {
+	var deleted int64
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		var names [][]byte
+		err := tx.ForEach(func(name []byte, bucket *bolt.Bucket) error {
+			if match(name) {
+				names = append(names, append([]byte(nil), name...))
+				deleted += int64(bucket.Stats().KeyN)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+			if onProgress != nil {
+				onProgress(deleted)
+			}
+		}
+
+		if bucket := tx.Bucket(metadataBucket); bucket != nil && bucket.Get([]byte(metadata)) != nil {
+			deleted++
+			return bucket.Delete([]byte(metadata))
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+func (s *boltStore) ClearCognates(ctx context.Context, onProgress func(deleted int64)) (int64, error) {
+	deleted, err := s.deleteBuckets(func(name []byte) bool {
+		return isVersionBucket(name) ||
+			bytes.Equal(name, datasetBucket) ||
+			bytes.Equal(name, versionsBucket) ||
+			bytes.Equal(name, jobsBucket)
+	}, "cognates", onProgress)
+	if err != nil {
+		return deleted, fmt.Errorf("failed to clear cognates: %w", err)
+	}
+	return deleted, nil
+}
+
+func (s *boltStore) ClearLanguages(ctx context.Context, onProgress func(deleted int64)) (int64, error) {
+	deleted, err := s.deleteBuckets(func(name []byte) bool {
+		return bytes.Equal(name, languagesBucket)
+	}, "languages", onProgress)
+	if err != nil {
+		return deleted, fmt.Errorf("failed to clear languages: %w", err)
+	}
+	return deleted, nil
+}
+
+func (s *boltStore) ClearConcepts(ctx context.Context, onProgress func(deleted int64)) (int64, error) {
+	deleted, err := s.deleteBuckets(func(name []byte) bool {
+		return bytes.Equal(name, synsetsBucket) || bytes.Equal(name, textBucket)
+	}, "concepts", onProgress)
+	if err != nil {
+		return deleted, fmt.Errorf("failed to clear concepts: %w", err)
+	}
+	return deleted, nil
+}
+
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
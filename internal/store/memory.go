@@ -0,0 +1,691 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cognet-world-inquiry-service/internal/model"
+)
+
+// memoryIndex is a sorted set kept as a score map, sorted lazily on read
+type memoryIndex struct {
+	scores map[string]PrefixEntry
+	sorted []PrefixEntry
+	dirty  bool
+}
+
+func newMemoryIndex() *memoryIndex {
+	return &memoryIndex{scores: make(map[string]PrefixEntry)}
+}
+
+func (idx *memoryIndex) add(entry WordEntry) {
+	member := PrefixEntry{Word: entry.Word, Lang: entry.Lang, ConceptID: entry.ConceptID}
+	key := member.Member()
+
+	current, ok := idx.scores[key]
+	if !ok {
+		current = member
+		current.Score = entry.BaseScore
+	}
+	current.Score--
+	idx.scores[key] = current
+	idx.dirty = true
+}
+
+func (idx *memoryIndex) rangeEntries(offset, count int64) []PrefixEntry {
+	if idx.dirty {
+		idx.sorted = idx.sorted[:0]
+		for _, entry := range idx.scores {
+			idx.sorted = append(idx.sorted, entry)
+		}
+		sort.Slice(idx.sorted, func(i, j int) bool {
+			if idx.sorted[i].Score != idx.sorted[j].Score {
+				return idx.sorted[i].Score < idx.sorted[j].Score
+			}
+			return idx.sorted[i].Member() < idx.sorted[j].Member()
+		})
+		idx.dirty = false
+	}
+
+	if offset >= int64(len(idx.sorted)) {
+		return []PrefixEntry{}
+	}
+	end := offset + count
+	if end > int64(len(idx.sorted)) {
+		end = int64(len(idx.sorted))
+	}
+	return append([]PrefixEntry(nil), idx.sorted[offset:end]...)
+}
+
+func (idx *memoryIndex) clone() *memoryIndex {
+	copied := newMemoryIndex()
+	for key, entry := range idx.scores {
+		copied.scores[key] = entry
+	}
+	copied.dirty = true
+	return copied
+}
+
+// memoryDataset holds the cognate data of one dataset version
+type memoryDataset struct {
+	concepts map[string]map[string]model.Cognate // concept -> pair key -> cognate
+	prefixes map[string]*memoryIndex             // "lang|prefix", lang empty for all
+	words    map[string]map[WordSense]bool
+	langs    map[string]bool
+}
+
+func newMemoryDataset() *memoryDataset {
+	return &memoryDataset{
+		concepts: make(map[string]map[string]model.Cognate),
+		prefixes: make(map[string]*memoryIndex),
+		words:    make(map[string]map[WordSense]bool),
+		langs:    make(map[string]bool),
+	}
+}
+
+func (ds *memoryDataset) clone() *memoryDataset {
+	copied := newMemoryDataset()
+	for conceptID, pairs := range ds.concepts {
+		copiedPairs := make(map[string]model.Cognate, len(pairs))
+		for pairKey, cognate := range pairs {
+			copiedPairs[pairKey] = cognate
+		}
+		copied.concepts[conceptID] = copiedPairs
+	}
+	for key, idx := range ds.prefixes {
+		copied.prefixes[key] = idx.clone()
+	}
+	for word, senses := range ds.words {
+		copiedSenses := make(map[WordSense]bool, len(senses))
+		for sense := range senses {
+			copiedSenses[sense] = true
+		}
+		copied.words[word] = copiedSenses
+	}
+	for lang := range ds.langs {
+		copied.langs[lang] = true
+	}
+	return copied
+}
+
+// memoryTextIndex is the full-text index of the concept metadata
+type memoryTextIndex struct {
+	docs        map[string]map[string]int // conceptID -> term -> frequency
+	postings    map[string]map[string]int // term -> conceptID -> frequency
+	totalLength int64
+}
+
+func newMemoryTextIndex() *memoryTextIndex {
+	return &memoryTextIndex{
+		docs:     make(map[string]map[string]int),
+		postings: make(map[string]map[string]int),
+	}
+}
+
+type expiringToken struct {
+	scope     string
+	expiresAt time.Time
+}
+
+type expiringLease struct {
+	owner     string
+	expiresAt time.Time
+}
+
+type expiringJob struct {
+	job       model.ImportJob
+	expiresAt time.Time
+}
+
+type memoryStore struct {
+	mu sync.RWMutex
+
+	nextVersion   int64
+	activeVersion int64
+	versions      map[int64]model.DatasetVersion
+	datasets      map[int64]*memoryDataset
+
+	languages   map[string]model.LanguageInfo
+	concepts    map[string]model.ConceptInfo
+	textIndex   *memoryTextIndex
+	metadata    map[string]map[string]interface{}
+	jobs        map[string]expiringJob
+	clearTokens map[string]expiringToken
+	leases      map[string]expiringLease
+}
+
+// NewMemoryStore keeps everything in process memory. Data does not survive
+// a restart and is not shared between replicas; it is meant for tests and
+// single binary local runs.
+func NewMemoryStore() CognateStore {
+	return &memoryStore{
+		versions:    make(map[int64]model.DatasetVersion),
+		datasets:    make(map[int64]*memoryDataset),
+		languages:   make(map[string]model.LanguageInfo),
+		concepts:    make(map[string]model.ConceptInfo),
+		textIndex:   newMemoryTextIndex(),
+		metadata:    make(map[string]map[string]interface{}),
+		jobs:        make(map[string]expiringJob),
+		clearTokens: make(map[string]expiringToken),
+		leases:      make(map[string]expiringLease),
+	}
+}
+
+// dataset returns the data of a version, creating it for writes
+func (s *memoryStore) dataset(version int64, create bool) *memoryDataset {
+	ds, ok := s.datasets[version]
+	if !ok && create {
+		ds = newMemoryDataset()
+		s.datasets[version] = ds
+	}
+	return ds
+}
+
+func (s *memoryStore) NextVersion(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextVersion++
+	return s.nextVersion, nil
+}
+
+func (s *memoryStore) ActiveVersion(ctx context.Context) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.activeVersion, nil
+}
+
+func (s *memoryStore) SetActiveVersion(ctx context.Context, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activeVersion = version
+	return nil
+}
+
+func (s *memoryStore) SaveVersion(ctx context.Context, meta *model.DatasetVersion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.versions[meta.Version] = *meta
+	return nil
+}
+
+func (s *memoryStore) GetVersion(ctx context.Context, version int64) (*model.DatasetVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	meta, ok := s.versions[version]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &meta, nil
+}
+
+func (s *memoryStore) ListVersions(ctx context.Context) ([]model.DatasetVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions := make([]model.DatasetVersion, 0, len(s.versions))
+	for _, meta := range s.versions {
+		versions = append(versions, meta)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version > versions[j].Version
+	})
+	return versions, nil
+}
+
+func (s *memoryStore) CopyVersion(ctx context.Context, from, to int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if source := s.dataset(from, false); source != nil {
+		s.datasets[to] = source.clone()
+	}
+	return nil
+}
+
+func (s *memoryStore) DeleteVersion(ctx context.Context, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.datasets, version)
+	delete(s.versions, version)
+	return nil
+}
+
+func (s *memoryStore) PutCognates(ctx context.Context, version int64, cognates []model.Cognate, overwrite bool) ([]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ds := s.dataset(version, true)
+	created := make([]bool, len(cognates))
+	for i, cognate := range cognates {
+		pairs, ok := ds.concepts[cognate.ConceptID]
+		if !ok {
+			pairs = make(map[string]model.Cognate)
+			ds.concepts[cognate.ConceptID] = pairs
+		}
+
+		pairKey := cognate.PairKey()
+		_, exists := pairs[pairKey]
+		if !exists || overwrite {
+			pairs[pairKey] = cognate
+		}
+		created[i] = !exists
+	}
+	return created, nil
+}
+
+func (s *memoryStore) GetConcept(ctx context.Context, version int64, conceptID string) ([]model.Cognate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ds := s.dataset(version, false)
+	if ds == nil {
+		return []model.Cognate{}, nil
+	}
+
+	pairs := ds.concepts[conceptID]
+	pairKeys := make([]string, 0, len(pairs))
+	for pairKey := range pairs {
+		pairKeys = append(pairKeys, pairKey)
+	}
+	sort.Strings(pairKeys)
+
+	cognates := make([]model.Cognate, 0, len(pairs))
+	for _, pairKey := range pairKeys {
+		cognates = append(cognates, pairs[pairKey])
+	}
+	return cognates, nil
+}
+
+// ScanConcepts snapshots the concept IDs, so fn runs without holding the
+// lock and may be slow
+func (s *memoryStore) ScanConcepts(ctx context.Context, version int64, conceptPrefix string, fn func(conceptID string, cognates []model.Cognate) error) error {
+	s.mu.RLock()
+	var conceptIDs []string
+	if ds := s.dataset(version, false); ds != nil {
+		conceptIDs = make([]string, 0, len(ds.concepts))
+		for conceptID := range ds.concepts {
+			if strings.HasPrefix(conceptID, conceptPrefix) {
+				conceptIDs = append(conceptIDs, conceptID)
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, conceptID := range conceptIDs {
+		cognates, err := s.GetConcept(ctx, version, conceptID)
+		if err != nil {
+			return err
+		}
+		if len(cognates) == 0 {
+			continue
+		}
+		if err := fn(conceptID, cognates); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) IndexWords(ctx context.Context, version int64, entries []WordEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ds := s.dataset(version, true)
+	for _, entry := range entries {
+		for _, prefix := range entry.Prefixes {
+			for _, key := range []string{"|" + prefix, entry.Lang + "|" + prefix} {
+				idx, ok := ds.prefixes[key]
+				if !ok {
+					idx = newMemoryIndex()
+					ds.prefixes[key] = idx
+				}
+				idx.add(entry)
+			}
+		}
+
+		senses, ok := ds.words[entry.Word]
+		if !ok {
+			senses = make(map[WordSense]bool)
+			ds.words[entry.Word] = senses
+		}
+		senses[WordSense{ConceptID: entry.ConceptID, Lang: entry.Lang}] = true
+		ds.langs[entry.Lang] = true
+	}
+	return nil
+}
+
+func (s *memoryStore) RangePrefix(ctx context.Context, version int64, lang, prefix string, offset, count int64) ([]PrefixEntry, error) {
+	// Reads may sort an index, which mutates it
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ds := s.dataset(version, false)
+	if ds == nil || count <= 0 {
+		return []PrefixEntry{}, nil
+	}
+	idx, ok := ds.prefixes[lang+"|"+prefix]
+	if !ok {
+		return []PrefixEntry{}, nil
+	}
+	return idx.rangeEntries(offset, count), nil
+}
+
+func (s *memoryStore) WordSenses(ctx context.Context, version int64, word string) ([]WordSense, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ds := s.dataset(version, false)
+	if ds == nil {
+		return []WordSense{}, nil
+	}
+
+	senses := make([]WordSense, 0, len(ds.words[word]))
+	for sense := range ds.words[word] {
+		senses = append(senses, sense)
+	}
+	return senses, nil
+}
+
+func (s *memoryStore) DatasetLanguages(ctx context.Context, version int64) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	codes := make([]string, 0)
+	if ds := s.dataset(version, false); ds != nil {
+		for lang := range ds.langs {
+			codes = append(codes, lang)
+		}
+	}
+	sort.Strings(codes)
+	return codes, nil
+}
+
+func (s *memoryStore) SaveLanguages(ctx context.Context, languages []model.LanguageInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, info := range languages {
+		s.languages[info.Code] = info
+	}
+	return nil
+}
+
+func (s *memoryStore) GetLanguage(ctx context.Context, code string) (model.LanguageInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	info, ok := s.languages[code]
+	if !ok {
+		return model.LanguageInfo{}, ErrNotFound
+	}
+	return info, nil
+}
+
+func (s *memoryStore) ListLanguages(ctx context.Context) ([]model.LanguageInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	languages := make([]model.LanguageInfo, 0, len(s.languages))
+	for _, info := range s.languages {
+		languages = append(languages, info)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		return languages[i].Code < languages[j].Code
+	})
+	return languages, nil
+}
+
+func (s *memoryStore) DeleteLanguage(ctx context.Context, code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.languages[code]; !ok {
+		return ErrNotFound
+	}
+	delete(s.languages, code)
+	return nil
+}
+
+func (s *memoryStore) SaveConcepts(ctx context.Context, concepts []model.ConceptInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, info := range concepts {
+		s.concepts[info.ConceptID] = info
+	}
+	return nil
+}
+
+func (s *memoryStore) GetConcepts(ctx context.Context, conceptIDs []string) (map[string]model.ConceptInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	concepts := make(map[string]model.ConceptInfo, len(conceptIDs))
+	for _, conceptID := range conceptIDs {
+		if info, ok := s.concepts[conceptID]; ok {
+			concepts[conceptID] = info
+		}
+	}
+	return concepts, nil
+}
+
+func (s *memoryStore) IndexConceptTerms(ctx context.Context, docs []ConceptTerms) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := s.textIndex
+	for _, doc := range docs {
+		if old, ok := idx.docs[doc.ConceptID]; ok {
+			for term := range old {
+				delete(idx.postings[term], doc.ConceptID)
+				if len(idx.postings[term]) == 0 {
+					delete(idx.postings, term)
+				}
+			}
+			idx.totalLength -= int64(termCount(old))
+			delete(idx.docs, doc.ConceptID)
+		}
+
+		if len(doc.Terms) == 0 {
+			continue
+		}
+		terms := make(map[string]int, len(doc.Terms))
+		for term, frequency := range doc.Terms {
+			terms[term] = frequency
+			if idx.postings[term] == nil {
+				idx.postings[term] = make(map[string]int)
+			}
+			idx.postings[term][doc.ConceptID] = frequency
+		}
+		idx.docs[doc.ConceptID] = terms
+		idx.totalLength += int64(termCount(terms))
+	}
+	return nil
+}
+
+func (s *memoryStore) MatchConceptTerms(ctx context.Context, terms []string) (*TermMatches, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	idx := s.textIndex
+	matches := &TermMatches{
+		Postings:    make(map[string]map[string]int, len(terms)),
+		Lengths:     make(map[string]int),
+		Concepts:    int64(len(idx.docs)),
+		TotalLength: idx.totalLength,
+	}
+	for _, term := range terms {
+		concepts := make(map[string]int, len(idx.postings[term]))
+		for conceptID, frequency := range idx.postings[term] {
+			concepts[conceptID] = frequency
+			matches.Lengths[conceptID] = termCount(idx.docs[conceptID])
+		}
+		matches.Postings[term] = concepts
+	}
+	return matches, nil
+}
+
+// PublishLanguageChange has nobody to notify, a memory store is never shared
+func (s *memoryStore) PublishLanguageChange(ctx context.Context) error {
+	return nil
+}
+
+func (s *memoryStore) LanguageChanges(ctx context.Context) <-chan struct{} {
+	return nil
+}
+
+func (s *memoryStore) SaveMetadata(ctx context.Context, name string, metadata map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metadata[name] = metadata
+	return nil
+}
+
+func (s *memoryStore) SaveImportJob(ctx context.Context, job *model.ImportJob, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if _, ok := s.jobs[job.ID]; !ok {
+		// Purge expired jobs whenever a new one is added
+		for id, stored := range s.jobs {
+			if now.After(stored.expiresAt) {
+				delete(s.jobs, id)
+			}
+		}
+	}
+	s.jobs[job.ID] = expiringJob{job: *job, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *memoryStore) GetImportJob(ctx context.Context, id string) (*model.ImportJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stored, ok := s.jobs[id]
+	if !ok || time.Now().After(stored.expiresAt) {
+		return nil, ErrNotFound
+	}
+	return &stored.job, nil
+}
+
+func (s *memoryStore) SaveClearToken(ctx context.Context, token, scope string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for stale, stored := range s.clearTokens {
+		if now.After(stored.expiresAt) {
+			delete(s.clearTokens, stale)
+		}
+	}
+	s.clearTokens[token] = expiringToken{scope: scope, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *memoryStore) TakeClearToken(ctx context.Context, token string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.clearTokens[token]
+	delete(s.clearTokens, token)
+	if !ok || time.Now().After(stored.expiresAt) {
+		return "", ErrNotFound
+	}
+	return stored.scope, nil
+}
+
+// leaseOwner returns the owner of a lease that has not expired
+func (s *memoryStore) leaseOwner(name string) string {
+	lease, ok := s.leases[name]
+	if !ok || time.Now().After(lease.expiresAt) {
+		return ""
+	}
+	return lease.owner
+}
+
+func (s *memoryStore) AcquireLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.leaseOwner(name) != "" {
+		return false, nil
+	}
+	now := time.Now()
+	for stale, lease := range s.leases {
+		if now.After(lease.expiresAt) {
+			delete(s.leases, stale)
+		}
+	}
+	s.leases[name] = expiringLease{owner: owner, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (s *memoryStore) RenewLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.leaseOwner(name) != owner {
+		return false, nil
+	}
+	s.leases[name] = expiringLease{owner: owner, expiresAt: time.Now().Add(ttl)}
+	return true, nil
+}
+
+func (s *memoryStore) ReleaseLease(ctx context.Context, name, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.leaseOwner(name) == owner {
+		delete(s.leases, name)
+	}
+	return nil
+}
+
+func (s *memoryStore) LeaseOwner(ctx context.Context, name string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.leaseOwner(name), nil
+}
+
+func (s *memoryStore) ClearCognates(ctx context.Context, onProgress func(deleted int64)) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := int64(len(s.datasets) + len(s.versions) + len(s.jobs))
+	s.nextVersion = 0
+	s.activeVersion = 0
+	s.versions = make(map[int64]model.DatasetVersion)
+	s.datasets = make(map[int64]*memoryDataset)
+	s.jobs = make(map[string]expiringJob)
+	delete(s.metadata, "cognates")
+
+	if onProgress != nil {
+		onProgress(deleted)
+	}
+	return deleted, nil
+}
+
+func (s *memoryStore) ClearLanguages(ctx context.Context, onProgress func(deleted int64)) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := int64(len(s.languages))
+	s.languages = make(map[string]model.LanguageInfo)
+	delete(s.metadata, "languages")
+
+	if onProgress != nil {
+		onProgress(deleted)
+	}
+	return deleted, nil
+}
+
+func (s *memoryStore) ClearConcepts(ctx context.Context, onProgress func(deleted int64)) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := int64(len(s.concepts))
+	s.concepts = make(map[string]model.ConceptInfo)
+	s.textIndex = newMemoryTextIndex()
+	delete(s.metadata, "concepts")
+
+	if onProgress != nil {
+		onProgress(deleted)
+	}
+	return deleted, nil
+}
+
+func (s *memoryStore) Close() error {
+	return nil
+}
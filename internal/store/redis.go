@@ -0,0 +1,893 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cognet-world-inquiry-service/internal/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Every dataset version lives in its own keyspace, v<N>:concept:...,
+// v<N>:prefix:... and so on. Searches read through dataset:active.
+const (
+	activeVersionKey  = "dataset:active"
+	versionCounterKey = "dataset:next"
+	versionIndexKey   = "dataset:versions"
+
+	// languageChangesChannel announces language imports to every replica
+	languageChangesChannel = "languages:changed"
+)
+
+type redisStore struct {
+	redisClient *redis.Client
+}
+
+func NewRedisStore(redisClient *redis.Client) CognateStore {
+	return &redisStore{
+		redisClient: redisClient,
+	}
+}
+
+// versionKeyspace is the key prefix of a dataset version
+func versionKeyspace(version int64) string {
+	return fmt.Sprintf("v%d:", version)
+}
+
+func versionMetadataKey(version int64) string {
+	return fmt.Sprintf("dataset:version:%d", version)
+}
+
+func importJobKey(id string) string {
+	return fmt.Sprintf("import:job:%s", id)
+}
+
+func conceptInfoKey(conceptID string) string {
+	return fmt.Sprintf("synset:%s", conceptID)
+}
+
+// The full-text index keeps the terms of each concept in text:doc:<id>, the
+// concepts of each term in text:term:<term>, concept lengths in
+// text:lengths and totals in text:stats
+const (
+	textLengthsKey = "text:lengths"
+	textStatsKey   = "text:stats"
+)
+
+func textDocKey(conceptID string) string {
+	return fmt.Sprintf("text:doc:%s", conceptID)
+}
+
+func textTermKey(term string) string {
+	return fmt.Sprintf("text:term:%s", term)
+}
+
+// Leases live outside the key patterns that clearing deletes, they expire on
+// their own
+func leaseKey(name string) string {
+	return fmt.Sprintf("lease:%s", name)
+}
+
+// Renewing and releasing only touch a lease that still has the same owner
+var (
+	renewLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+func clearTokenKey(token string) string {
+	return fmt.Sprintf("import:clear:token:%s", token)
+}
+
+func (s *redisStore) NextVersion(ctx context.Context) (int64, error) {
+	version, err := s.redisClient.Incr(ctx, versionCounterKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate dataset version: %w", err)
+	}
+	return version, nil
+}
+
+func (s *redisStore) ActiveVersion(ctx context.Context) (int64, error) {
+	value, err := s.redisClient.Get(ctx, activeVersionKey).Result()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get active dataset version: %w", err)
+	}
+
+	version, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid active dataset version %q: %w", value, err)
+	}
+	return version, nil
+}
+
+// SetActiveVersion is a single SET, readers see either the old or the new
+// version
+func (s *redisStore) SetActiveVersion(ctx context.Context, version int64) error {
+	if err := s.redisClient.Set(ctx, activeVersionKey, version, 0).Err(); err != nil {
+		return fmt.Errorf("failed to activate dataset version: %w", err)
+	}
+	return nil
+}
+
+func (s *redisStore) SaveVersion(ctx context.Context, meta *model.DatasetVersion) error {
+	jsonData, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dataset version: %w", err)
+	}
+
+	pipeline := s.redisClient.Pipeline()
+	pipeline.Set(ctx, versionMetadataKey(meta.Version), jsonData, 0)
+	pipeline.ZAdd(ctx, versionIndexKey, redis.Z{Score: float64(meta.Version), Member: meta.Version})
+	if _, err := pipeline.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store dataset version: %w", err)
+	}
+	return nil
+}
+
+func (s *redisStore) GetVersion(ctx context.Context, version int64) (*model.DatasetVersion, error) {
+	data, err := s.redisClient.Get(ctx, versionMetadataKey(version)).Result()
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dataset version: %w", err)
+	}
+
+	var meta model.DatasetVersion
+	if err := json.Unmarshal([]byte(data), &meta); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dataset version: %w", err)
+	}
+	return &meta, nil
+}
+
+func (s *redisStore) ListVersions(ctx context.Context) ([]model.DatasetVersion, error) {
+	members, err := s.redisClient.ZRevRange(ctx, versionIndexKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dataset versions: %w", err)
+	}
+
+	versions := make([]model.DatasetVersion, 0, len(members))
+	for _, member := range members {
+		version, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		meta, err := s.GetVersion(ctx, version)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, *meta)
+	}
+
+	return versions, nil
+}
+
+// CopyVersion duplicates every key of one version into another, server side
+func (s *redisStore) CopyVersion(ctx context.Context, from, to int64) error {
+	source := versionKeyspace(from)
+	target := versionKeyspace(to)
+
+	iter := s.redisClient.Scan(ctx, 0, source+"*", 1000).Iterator()
+	pipeline := s.redisClient.Pipeline()
+	pending := 0
+	for iter.Next(ctx) {
+		key := iter.Val()
+		pipeline.Copy(ctx, key, target+strings.TrimPrefix(key, source), 0, true)
+		pending++
+		if pending == 1000 {
+			if _, err := pipeline.Exec(ctx); err != nil {
+				return err
+			}
+			pipeline = s.redisClient.Pipeline()
+			pending = 0
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if pending > 0 {
+		if _, err := pipeline.Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *redisStore) DeleteVersion(ctx context.Context, version int64) error {
+	if _, err := s.deleteMatching(ctx, keyPattern{match: versionKeyspace(version) + "*"}, nil); err != nil {
+		return err
+	}
+	if err := s.redisClient.Del(ctx, versionMetadataKey(version)).Err(); err != nil {
+		return err
+	}
+	return s.redisClient.ZRem(ctx, versionIndexKey, version).Err()
+}
+
+// PutCognates stores each pair as one field of the concept:<id> hash, so a
+// pair can never be stored twice
+func (s *redisStore) PutCognates(ctx context.Context, version int64, cognates []model.Cognate, overwrite bool) ([]bool, error) {
+	keyspace := versionKeyspace(version)
+	pipeline := s.redisClient.Pipeline()
+	inserts := make([]*redis.BoolCmd, len(cognates))
+	updates := make([]*redis.IntCmd, len(cognates))
+
+	for i, cognate := range cognates {
+		jsonData, err := json.Marshal(cognate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal cognate: %w", err)
+		}
+
+		conceptKey := fmt.Sprintf("%sconcept:%s", keyspace, cognate.ConceptID)
+		if overwrite {
+			updates[i] = pipeline.HSet(ctx, conceptKey, cognate.PairKey(), jsonData)
+		} else {
+			inserts[i] = pipeline.HSetNX(ctx, conceptKey, cognate.PairKey(), jsonData)
+		}
+	}
+
+	if _, err := pipeline.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	created := make([]bool, len(cognates))
+	for i := range cognates {
+		if overwrite {
+			created[i] = updates[i].Val() == 1
+		} else {
+			created[i] = inserts[i].Val()
+		}
+	}
+	return created, nil
+}
+
+func (s *redisStore) GetConcept(ctx context.Context, version int64, conceptID string) ([]model.Cognate, error) {
+	pairs, err := s.redisClient.HGetAll(ctx, fmt.Sprintf("%sconcept:%s", versionKeyspace(version), conceptID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cognates: %w", err)
+	}
+	return decodeConcept(pairs)
+}
+
+// decodeConcept turns a concept hash into its cognates, ordered by pair key
+func decodeConcept(pairs map[string]string) ([]model.Cognate, error) {
+	pairKeys := make([]string, 0, len(pairs))
+	for pairKey := range pairs {
+		pairKeys = append(pairKeys, pairKey)
+	}
+	sort.Strings(pairKeys)
+
+	cognates := make([]model.Cognate, 0, len(pairs))
+	for _, pairKey := range pairKeys {
+		var cognate model.Cognate
+		if err := json.Unmarshal([]byte(pairs[pairKey]), &cognate); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal cognate: %w", err)
+		}
+		cognates = append(cognates, cognate)
+	}
+
+	return cognates, nil
+}
+
+// globEscaper quotes the characters SCAN MATCH patterns treat specially
+var globEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
+// ScanConcepts walks the concept hashes with SCAN, fetching them in
+// pipelined batches
+func (s *redisStore) ScanConcepts(ctx context.Context, version int64, conceptPrefix string, fn func(conceptID string, cognates []model.Cognate) error) error {
+	keyPrefix := versionKeyspace(version) + "concept:"
+	iter := s.redisClient.Scan(ctx, 0, keyPrefix+globEscaper.Replace(conceptPrefix)+"*", 1000).Iterator()
+
+	keys := make([]string, 0, 1000)
+	flush := func() error {
+		pipeline := s.redisClient.Pipeline()
+		cmds := make([]*redis.MapStringStringCmd, len(keys))
+		for i, key := range keys {
+			cmds[i] = pipeline.HGetAll(ctx, key)
+		}
+		if _, err := pipeline.Exec(ctx); err != nil {
+			return fmt.Errorf("failed to fetch cognates: %w", err)
+		}
+
+		for i, key := range keys {
+			cognates, err := decodeConcept(cmds[i].Val())
+			if err != nil {
+				return err
+			}
+			if len(cognates) == 0 {
+				// Deleted since the scan
+				continue
+			}
+			if err := fn(strings.TrimPrefix(key, keyPrefix), cognates); err != nil {
+				return err
+			}
+		}
+		keys = keys[:0]
+		return nil
+	}
+
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == cap(keys) {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		return flush()
+	}
+	return nil
+}
+
+func prefixKey(keyspace, lang, prefix string) string {
+	if lang == "" {
+		return fmt.Sprintf("%sprefix:%s", keyspace, prefix)
+	}
+	return fmt.Sprintf("%slangprefix:%s:%s", keyspace, lang, prefix)
+}
+
+// IndexWords writes the ranked prefix:<p> and langprefix:<lang>:<p> sorted
+// sets, the word:<word> sets of exact matches and the langs set
+func (s *redisStore) IndexWords(ctx context.Context, version int64, entries []WordEntry) error {
+	keyspace := versionKeyspace(version)
+	pipeline := s.redisClient.Pipeline()
+
+	for _, entry := range entries {
+		member := fmt.Sprintf("%s|%s|%s", entry.Word, entry.Lang, entry.ConceptID)
+		for _, prefix := range entry.Prefixes {
+			for _, key := range []string{prefixKey(keyspace, "", prefix), prefixKey(keyspace, entry.Lang, prefix)} {
+				pipeline.ZAddNX(ctx, key, redis.Z{Score: entry.BaseScore, Member: member})
+				pipeline.ZIncrBy(ctx, key, -1, member)
+			}
+		}
+
+		pipeline.SAdd(ctx, fmt.Sprintf("%sword:%s", keyspace, entry.Word), fmt.Sprintf("%s|%s", entry.ConceptID, entry.Lang))
+		pipeline.SAdd(ctx, keyspace+"langs", entry.Lang)
+	}
+
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+func (s *redisStore) RangePrefix(ctx context.Context, version int64, lang, prefix string, offset, count int64) ([]PrefixEntry, error) {
+	if count <= 0 {
+		return []PrefixEntry{}, nil
+	}
+
+	members, err := s.redisClient.ZRangeWithScores(ctx, prefixKey(versionKeyspace(version), lang, prefix), offset, offset+count-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]PrefixEntry, 0, len(members))
+	for _, z := range members {
+		// member format: "word|language|conceptID"
+		parts := strings.Split(z.Member.(string), "|")
+		if len(parts) != 3 {
+			continue
+		}
+		entries = append(entries, PrefixEntry{Word: parts[0], Lang: parts[1], ConceptID: parts[2], Score: z.Score})
+	}
+	return entries, nil
+}
+
+func (s *redisStore) WordSenses(ctx context.Context, version int64, word string) ([]WordSense, error) {
+	// members format: "conceptID|language"
+	members, err := s.redisClient.SMembers(ctx, fmt.Sprintf("%sword:%s", versionKeyspace(version), word)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch word index: %w", err)
+	}
+
+	senses := make([]WordSense, 0, len(members))
+	for _, member := range members {
+		parts := strings.Split(member, "|")
+		if len(parts) != 2 {
+			continue
+		}
+		senses = append(senses, WordSense{ConceptID: parts[0], Lang: parts[1]})
+	}
+	return senses, nil
+}
+
+func (s *redisStore) DatasetLanguages(ctx context.Context, version int64) ([]string, error) {
+	codes, err := s.redisClient.SMembers(ctx, versionKeyspace(version)+"langs").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch dataset languages: %w", err)
+	}
+	sort.Strings(codes)
+	return codes, nil
+}
+
+func (s *redisStore) SaveLanguages(ctx context.Context, languages []model.LanguageInfo) error {
+	pipeline := s.redisClient.Pipeline()
+	for _, info := range languages {
+		jsonData, err := json.Marshal(info)
+		if err != nil {
+			return fmt.Errorf("failed to marshal language info: %w", err)
+		}
+		pipeline.Set(ctx, fmt.Sprintf("lang:%s", info.Code), jsonData, 0)
+	}
+
+	if _, err := pipeline.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store languages in redis: %w", err)
+	}
+	return nil
+}
+
+func (s *redisStore) GetLanguage(ctx context.Context, code string) (model.LanguageInfo, error) {
+	data, err := s.redisClient.Get(ctx, fmt.Sprintf("lang:%s", code)).Result()
+	if err == redis.Nil {
+		return model.LanguageInfo{}, ErrNotFound
+	}
+	if err != nil {
+		return model.LanguageInfo{}, fmt.Errorf("failed to get language info: %w", err)
+	}
+
+	var langInfo model.LanguageInfo
+	if err := json.Unmarshal([]byte(data), &langInfo); err != nil {
+		return model.LanguageInfo{}, fmt.Errorf("failed to unmarshal language info: %w", err)
+	}
+
+	return langInfo, nil
+}
+
+func (s *redisStore) ListLanguages(ctx context.Context) ([]model.LanguageInfo, error) {
+	var keys []string
+	iter := s.redisClient.Scan(ctx, 0, "lang:*", 1000).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list languages: %w", err)
+	}
+	sort.Strings(keys)
+
+	languages := make([]model.LanguageInfo, 0, len(keys))
+	for start := 0; start < len(keys); start += 1000 {
+		end := start + 1000
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		values, err := s.redisClient.MGet(ctx, keys[start:end]...).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list languages: %w", err)
+		}
+		for _, value := range values {
+			data, ok := value.(string)
+			if !ok {
+				// Deleted since the scan
+				continue
+			}
+
+			var langInfo model.LanguageInfo
+			if err := json.Unmarshal([]byte(data), &langInfo); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal language info: %w", err)
+			}
+			languages = append(languages, langInfo)
+		}
+	}
+	return languages, nil
+}
+
+func (s *redisStore) DeleteLanguage(ctx context.Context, code string) error {
+	deleted, err := s.redisClient.Del(ctx, fmt.Sprintf("lang:%s", code)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to delete language info: %w", err)
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *redisStore) SaveConcepts(ctx context.Context, concepts []model.ConceptInfo) error {
+	pipeline := s.redisClient.Pipeline()
+	for _, info := range concepts {
+		jsonData, err := json.Marshal(info)
+		if err != nil {
+			return fmt.Errorf("failed to marshal concept info: %w", err)
+		}
+		pipeline.Set(ctx, conceptInfoKey(info.ConceptID), jsonData, 0)
+	}
+
+	if _, err := pipeline.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store concepts in redis: %w", err)
+	}
+	return nil
+}
+
+func (s *redisStore) GetConcepts(ctx context.Context, conceptIDs []string) (map[string]model.ConceptInfo, error) {
+	concepts := make(map[string]model.ConceptInfo, len(conceptIDs))
+	if len(conceptIDs) == 0 {
+		return concepts, nil
+	}
+
+	keys := make([]string, len(conceptIDs))
+	for i, conceptID := range conceptIDs {
+		keys[i] = conceptInfoKey(conceptID)
+	}
+	values, err := s.redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get concept info: %w", err)
+	}
+
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			// No metadata for this concept
+			continue
+		}
+
+		var info model.ConceptInfo
+		if err := json.Unmarshal([]byte(data), &info); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal concept info: %w", err)
+		}
+		concepts[info.ConceptID] = info
+	}
+	return concepts, nil
+}
+
+func (s *redisStore) IndexConceptTerms(ctx context.Context, docs []ConceptTerms) error {
+	// Read the terms indexed before, their postings have to go
+	pipeline := s.redisClient.Pipeline()
+	previous := make([]*redis.MapStringStringCmd, len(docs))
+	for i, doc := range docs {
+		previous[i] = pipeline.HGetAll(ctx, textDocKey(doc.ConceptID))
+	}
+	if _, err := pipeline.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to read indexed terms: %w", err)
+	}
+
+	pipeline = s.redisClient.Pipeline()
+	for i, doc := range docs {
+		if old := previous[i].Val(); len(old) > 0 {
+			oldLength := 0
+			for term, frequency := range old {
+				pipeline.HDel(ctx, textTermKey(term), doc.ConceptID)
+				n, _ := strconv.Atoi(frequency)
+				oldLength += n
+			}
+			pipeline.Del(ctx, textDocKey(doc.ConceptID))
+			pipeline.HDel(ctx, textLengthsKey, doc.ConceptID)
+			pipeline.HIncrBy(ctx, textStatsKey, "concepts", -1)
+			pipeline.HIncrBy(ctx, textStatsKey, "length", int64(-oldLength))
+		}
+
+		if len(doc.Terms) == 0 {
+			continue
+		}
+		fields := make(map[string]interface{}, len(doc.Terms))
+		for term, frequency := range doc.Terms {
+			fields[term] = frequency
+			pipeline.HSet(ctx, textTermKey(term), doc.ConceptID, frequency)
+		}
+		length := termCount(doc.Terms)
+		pipeline.HSet(ctx, textDocKey(doc.ConceptID), fields)
+		pipeline.HSet(ctx, textLengthsKey, doc.ConceptID, length)
+		pipeline.HIncrBy(ctx, textStatsKey, "concepts", 1)
+		pipeline.HIncrBy(ctx, textStatsKey, "length", int64(length))
+	}
+
+	if _, err := pipeline.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to index concept terms: %w", err)
+	}
+	return nil
+}
+
+func (s *redisStore) MatchConceptTerms(ctx context.Context, terms []string) (*TermMatches, error) {
+	pipeline := s.redisClient.Pipeline()
+	postings := make([]*redis.MapStringStringCmd, len(terms))
+	for i, term := range terms {
+		postings[i] = pipeline.HGetAll(ctx, textTermKey(term))
+	}
+	stats := pipeline.HMGet(ctx, textStatsKey, "concepts", "length")
+	if _, err := pipeline.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to match terms: %w", err)
+	}
+
+	matches := &TermMatches{
+		Postings: make(map[string]map[string]int, len(terms)),
+		Lengths:  make(map[string]int),
+	}
+	for i, term := range terms {
+		concepts := make(map[string]int, len(postings[i].Val()))
+		for conceptID, frequency := range postings[i].Val() {
+			concepts[conceptID], _ = strconv.Atoi(frequency)
+			matches.Lengths[conceptID] = 0
+		}
+		matches.Postings[term] = concepts
+	}
+	if values := stats.Val(); len(values) == 2 {
+		matches.Concepts = parseRedisInt(values[0])
+		matches.TotalLength = parseRedisInt(values[1])
+	}
+
+	if len(matches.Lengths) == 0 {
+		return matches, nil
+	}
+	conceptIDs := make([]string, 0, len(matches.Lengths))
+	for conceptID := range matches.Lengths {
+		conceptIDs = append(conceptIDs, conceptID)
+	}
+	lengths, err := s.redisClient.HMGet(ctx, textLengthsKey, conceptIDs...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read concept lengths: %w", err)
+	}
+	for i, conceptID := range conceptIDs {
+		matches.Lengths[conceptID] = int(parseRedisInt(lengths[i]))
+	}
+	return matches, nil
+}
+
+// parseRedisInt reads an HMGET value, nil for missing fields
+func parseRedisInt(value interface{}) int64 {
+	text, ok := value.(string)
+	if !ok {
+		return 0
+	}
+	n, _ := strconv.ParseInt(text, 10, 64)
+	return n
+}
+
+func (s *redisStore) PublishLanguageChange(ctx context.Context) error {
+	if err := s.redisClient.Publish(ctx, languageChangesChannel, "").Err(); err != nil {
+		return fmt.Errorf("failed to publish language change: %w", err)
+	}
+	return nil
+}
+
+// LanguageChanges subscribes to language change announcements until ctx is
+// done. Notifications are coalesced, a slow reader sees at most one pending.
+func (s *redisStore) LanguageChanges(ctx context.Context) <-chan struct{} {
+	pubsub := s.redisClient.Subscribe(ctx, languageChangesChannel)
+	changes := make(chan struct{}, 1)
+
+	go func() {
+		defer pubsub.Close()
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case changes <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return changes
+}
+
+// SaveMetadata stores import bookkeeping under import:<name>:metadata
+func (s *redisStore) SaveMetadata(ctx context.Context, name string, metadata map[string]interface{}) error {
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	if err := s.redisClient.Set(ctx, fmt.Sprintf("import:%s:metadata", name), metadataJSON, 0).Err(); err != nil {
+		return fmt.Errorf("failed to store metadata: %w", err)
+	}
+	return nil
+}
+
+func (s *redisStore) SaveImportJob(ctx context.Context, job *model.ImportJob, ttl time.Duration) error {
+	jsonData, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal import job: %w", err)
+	}
+
+	if err := s.redisClient.Set(ctx, importJobKey(job.ID), jsonData, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to store import job: %w", err)
+	}
+	return nil
+}
+
+func (s *redisStore) GetImportJob(ctx context.Context, id string) (*model.ImportJob, error) {
+	data, err := s.redisClient.Get(ctx, importJobKey(id)).Result()
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+
+	var job model.ImportJob
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal import job: %w", err)
+	}
+	return &job, nil
+}
+
+func (s *redisStore) SaveClearToken(ctx context.Context, token, scope string, ttl time.Duration) error {
+	if err := s.redisClient.Set(ctx, clearTokenKey(token), scope, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to store confirmation token: %w", err)
+	}
+	return nil
+}
+
+// TakeClearToken returns the scope of a token and invalidates it
+func (s *redisStore) TakeClearToken(ctx context.Context, token string) (string, error) {
+	scope, err := s.redisClient.GetDel(ctx, clearTokenKey(token)).Result()
+	if err == redis.Nil {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check confirmation token: %w", err)
+	}
+	return scope, nil
+}
+
+func (s *redisStore) AcquireLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	acquired, err := s.redisClient.SetNX(ctx, leaseKey(name), owner, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease %s: %w", name, err)
+	}
+	return acquired, nil
+}
+
+func (s *redisStore) RenewLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	renewed, err := renewLeaseScript.Run(ctx, s.redisClient, []string{leaseKey(name)}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to renew lease %s: %w", name, err)
+	}
+	return renewed == 1, nil
+}
+
+func (s *redisStore) ReleaseLease(ctx context.Context, name, owner string) error {
+	if err := releaseLeaseScript.Run(ctx, s.redisClient, []string{leaseKey(name)}, owner).Err(); err != nil {
+		return fmt.Errorf("failed to release lease %s: %w", name, err)
+	}
+	return nil
+}
+
+func (s *redisStore) LeaseOwner(ctx context.Context, name string) (string, error) {
+	owner, err := s.redisClient.Get(ctx, leaseKey(name)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read lease %s: %w", name, err)
+	}
+	return owner, nil
+}
+
+// keyPattern is a SCAN pattern, optionally narrowed by a regexp for patterns
+// that glob cannot express precisely
+type keyPattern struct {
+	match  string
+	filter *regexp.Regexp
+}
+
+var (
+	cognateKeyPatterns = []keyPattern{
+		{match: "v[0-9]*:*", filter: regexp.MustCompile(`^v[0-9]+:((concept|prefix|langprefix|word):|langs$)`)},
+		{match: "dataset:*"},
+		{match: "import:cognates:metadata"},
+		{match: "import:job:*"},
+		// Written before imports were versioned, nothing reads them anymore
+		{match: "concept:*"},
+		{match: "prefix:*"},
+		{match: "word:*"},
+		{match: "import:metadata"},
+	}
+	languageKeyPatterns = []keyPattern{
+		{match: "lang:*"},
+		{match: "import:languages:metadata"},
+	}
+	conceptKeyPatterns = []keyPattern{
+		{match: "synset:*"},
+		{match: "text:*"},
+		{match: "import:concepts:metadata"},
+	}
+)
+
+// ClearCognates deletes only this service's keys, leaving anything else in
+// a shared Redis instance untouched
+func (s *redisStore) ClearCognates(ctx context.Context, onProgress func(deleted int64)) (int64, error) {
+	return s.deletePatterns(ctx, cognateKeyPatterns, onProgress)
+}
+
+func (s *redisStore) ClearLanguages(ctx context.Context, onProgress func(deleted int64)) (int64, error) {
+	return s.deletePatterns(ctx, languageKeyPatterns, onProgress)
+}
+
+func (s *redisStore) ClearConcepts(ctx context.Context, onProgress func(deleted int64)) (int64, error) {
+	return s.deletePatterns(ctx, conceptKeyPatterns, onProgress)
+}
+
+func (s *redisStore) deletePatterns(ctx context.Context, patterns []keyPattern, onProgress func(deleted int64)) (int64, error) {
+	var total int64
+	for _, pattern := range patterns {
+		deleted, err := s.deleteMatching(ctx, pattern, func(deleted int64) {
+			if onProgress != nil {
+				onProgress(total + deleted)
+			}
+		})
+		total += deleted
+		if err != nil {
+			return total, fmt.Errorf("failed to clear %s: %w", pattern.match, err)
+		}
+	}
+	return total, nil
+}
+
+// deleteMatching unlinks every key matching pattern in SCAN sized batches and
+// returns how many keys were removed. onBatch, when set, receives the running
+// total after every batch.
+func (s *redisStore) deleteMatching(ctx context.Context, pattern keyPattern, onBatch func(deleted int64)) (int64, error) {
+	var deleted int64
+	iter := s.redisClient.Scan(ctx, 0, pattern.match, 1000).Iterator()
+	keys := make([]string, 0, 1000)
+
+	unlink := func() error {
+		n, err := s.redisClient.Unlink(ctx, keys...).Result()
+		if err != nil {
+			return err
+		}
+		deleted += n
+		keys = keys[:0]
+		if onBatch != nil {
+			onBatch(deleted)
+		}
+		return nil
+	}
+
+	for iter.Next(ctx) {
+		key := iter.Val()
+		if pattern.filter != nil && !pattern.filter.MatchString(key) {
+			continue
+		}
+
+		keys = append(keys, key)
+		if len(keys) == cap(keys) {
+			if err := unlink(); err != nil {
+				return deleted, err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, err
+	}
+	if len(keys) > 0 {
+		if err := unlink(); err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+func (s *redisStore) Close() error {
+	return s.redisClient.Close()
+}
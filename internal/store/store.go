@@ -0,0 +1,144 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cognet-world-inquiry-service/internal/model"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// CognateStore is the persistence behind search and import. Cognate data is
+// kept per dataset version; languages, jobs and the remaining bookkeeping are
+// shared by all versions.
+type CognateStore interface {
+	// Dataset versions
+	NextVersion(ctx context.Context) (int64, error)
+	ActiveVersion(ctx context.Context) (int64, error) // 0 when none is active
+	SetActiveVersion(ctx context.Context, version int64) error
+	SaveVersion(ctx context.Context, meta *model.DatasetVersion) error
+	GetVersion(ctx context.Context, version int64) (*model.DatasetVersion, error)
+	ListVersions(ctx context.Context) ([]model.DatasetVersion, error) // newest first
+	CopyVersion(ctx context.Context, from, to int64) error
+	DeleteVersion(ctx context.Context, version int64) error
+
+	// Concepts. PutCognates reports for each cognate whether its pair was new;
+	// existing pairs are only rewritten when overwrite is set.
+	PutCognates(ctx context.Context, version int64, cognates []model.Cognate, overwrite bool) ([]bool, error)
+	GetConcept(ctx context.Context, version int64, conceptID string) ([]model.Cognate, error)
+	// ScanConcepts calls fn with the cognates of every concept whose ID starts
+	// with conceptPrefix, in no particular order, and stops at the first error
+	// fn returns
+	ScanConcepts(ctx context.Context, version int64, conceptPrefix string, fn func(conceptID string, cognates []model.Cognate) error) error
+
+	// Prefix and word indexes. An empty lang ranges over all languages.
+	IndexWords(ctx context.Context, version int64, entries []WordEntry) error
+	RangePrefix(ctx context.Context, version int64, lang, prefix string, offset, count int64) ([]PrefixEntry, error)
+	WordSenses(ctx context.Context, version int64, word string) ([]WordSense, error)
+	// DatasetLanguages lists the language codes of every indexed word
+	DatasetLanguages(ctx context.Context, version int64) ([]string, error)
+
+	// Languages
+	SaveLanguages(ctx context.Context, languages []model.LanguageInfo) error
+	GetLanguage(ctx context.Context, code string) (model.LanguageInfo, error)
+	ListLanguages(ctx context.Context) ([]model.LanguageInfo, error) // ordered by code
+	DeleteLanguage(ctx context.Context, code string) error
+
+	// Concept metadata. GetConcepts leaves out concepts without metadata.
+	SaveConcepts(ctx context.Context, concepts []model.ConceptInfo) error
+	GetConcepts(ctx context.Context, conceptIDs []string) (map[string]model.ConceptInfo, error)
+
+	// Full-text index of the concept metadata. IndexConceptTerms replaces the
+	// terms indexed before for each concept, concept IDs must be unique
+	// within one call.
+	IndexConceptTerms(ctx context.Context, docs []ConceptTerms) error
+	MatchConceptTerms(ctx context.Context, terms []string) (*TermMatches, error)
+
+	// Language change notifications between replicas sharing the store.
+	// LanguageChanges returns nil for stores that cannot be shared.
+	PublishLanguageChange(ctx context.Context) error
+	LanguageChanges(ctx context.Context) <-chan struct{}
+
+	// Metadata
+	SaveMetadata(ctx context.Context, name string, metadata map[string]interface{}) error
+	SaveImportJob(ctx context.Context, job *model.ImportJob, ttl time.Duration) error
+	GetImportJob(ctx context.Context, id string) (*model.ImportJob, error)
+	SaveClearToken(ctx context.Context, token, scope string, ttl time.Duration) error
+	TakeClearToken(ctx context.Context, token string) (string, error)
+
+	// Leases are named locks that expire unless their owner renews them.
+	// AcquireLease and RenewLease report false when another owner holds the
+	// lease, LeaseOwner returns "" for a free lease.
+	AcquireLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	RenewLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, name, owner string) error
+	LeaseOwner(ctx context.Context, name string) (string, error)
+
+	// Clearing, onProgress receives the running number of deleted entries
+	ClearCognates(ctx context.Context, onProgress func(deleted int64)) (int64, error)
+	ClearLanguages(ctx context.Context, onProgress func(deleted int64)) (int64, error)
+	ClearConcepts(ctx context.Context, onProgress func(deleted int64)) (int64, error)
+
+	Close() error
+}
+
+// WordEntry adds one cognate pair occurrence of a word to the indexes. A new
+// word enters every prefix index at BaseScore, each entry for it then lowers
+// its score by one, so words with more cognates rank first.
+type WordEntry struct {
+	Word      string
+	Lang      string
+	ConceptID string
+	Prefixes  []string
+	BaseScore float64
+}
+
+// PrefixEntry is a prefix index member. Ranges are ordered by score, then
+// by Member.
+type PrefixEntry struct {
+	Word      string
+	Lang      string
+	ConceptID string
+	Score     float64
+}
+
+// Member is the stored form of the entry, "word|language|conceptID"
+func (e PrefixEntry) Member() string {
+	return fmt.Sprintf("%s|%s|%s", e.Word, e.Lang, e.ConceptID)
+}
+
+// ConceptTerms are the search terms of one concept with their frequency.
+// Empty Terms remove the concept from the index.
+type ConceptTerms struct {
+	ConceptID string
+	Terms     map[string]int
+}
+
+// TermMatches holds what ranking a full-text query needs
+type TermMatches struct {
+	// Postings maps each term to the concepts holding it and its frequency
+	Postings map[string]map[string]int
+	// Lengths is the number of terms of every concept in Postings
+	Lengths map[string]int
+	// Concepts and TotalLength cover the whole index
+	Concepts    int64
+	TotalLength int64
+}
+
+// termCount is the number of terms of a document
+func termCount(terms map[string]int) int {
+	count := 0
+	for _, frequency := range terms {
+		count += frequency
+	}
+	return count
+}
+
+// WordSense is one concept an exact word takes part in
+type WordSense struct {
+	ConceptID string
+	Lang      string
+}
@@ -0,0 +1,386 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"cognet-world-inquiry-service/internal/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// shortTTL expires entries within a test, Redis keeps milliseconds
+const shortTTL = 10 * time.Millisecond
+
+// forEachStore runs a test against every backend. Redis is only tested when
+// REDIS_TEST_ADDRESS names a scratch instance, its database is flushed.
+func forEachStore(t *testing.T, test func(t *testing.T, s CognateStore)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, NewMemoryStore())
+	})
+
+	t.Run("bolt", func(t *testing.T) {
+		s, err := NewBoltStore(filepath.Join(t.TempDir(), "cognet.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { s.Close() })
+		test(t, s)
+	})
+
+	t.Run("redis", func(t *testing.T) {
+		address := os.Getenv("REDIS_TEST_ADDRESS")
+		if address == "" {
+			t.Skip("REDIS_TEST_ADDRESS is not set")
+		}
+		client := redis.NewClient(&redis.Options{Addr: address})
+		if err := client.FlushDB(context.Background()).Err(); err != nil {
+			t.Fatal(err)
+		}
+		s := NewRedisStore(client)
+		t.Cleanup(func() { s.Close() })
+		test(t, s)
+	})
+}
+
+func TestPutCognates(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s CognateStore) {
+		ctx := context.Background()
+
+		bank := model.Cognate{ConceptID: "n00000001", Lang1: "eng", Word1: "bank", Lang2: "deu", Word2: "Bank"}
+		reversed := model.Cognate{ConceptID: "n00000001", Lang1: "deu", Word1: "Bank", Lang2: "eng", Word2: "bank", Translit1: "bank"}
+		other := model.Cognate{ConceptID: "n00000002", Lang1: "eng", Word1: "bank", Lang2: "deu", Word2: "Bank"}
+
+		tests := []struct {
+			name      string
+			cognates  []model.Cognate
+			overwrite bool
+			created   []bool
+			stored    model.Cognate
+		}{
+			{name: "new pairs", cognates: []model.Cognate{bank, other}, created: []bool{true, true}, stored: bank},
+			{name: "same pair reversed is kept", cognates: []model.Cognate{reversed}, created: []bool{false}, stored: bank},
+			{name: "same pair reversed is overwritten", cognates: []model.Cognate{reversed}, overwrite: true, created: []bool{false}, stored: reversed},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				created, err := s.PutCognates(ctx, 1, tt.cognates, tt.overwrite)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(created, tt.created) {
+					t.Errorf("created = %v, want %v", created, tt.created)
+				}
+
+				cognates, err := s.GetConcept(ctx, 1, "n00000001")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(cognates) != 1 || cognates[0] != tt.stored {
+					t.Errorf("stored %+v, want %+v", cognates, tt.stored)
+				}
+			})
+		}
+	})
+}
+
+func TestRangePrefix(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s CognateStore) {
+		ctx := context.Background()
+
+		entry := func(word, lang, conceptID string, baseScore float64) WordEntry {
+			return WordEntry{Word: word, Lang: lang, ConceptID: conceptID, Prefixes: []string{"ba"}, BaseScore: baseScore}
+		}
+		if err := s.IndexWords(ctx, 1, []WordEntry{
+			entry("bank", "eng", "n00000001", 4),
+			entry("bann", "deu", "n00000002", 3),
+			entry("ban", "eng", "n00000002", 2),
+			// Every cognate of a word lowers its score by one
+			entry("bank", "eng", "n00000001", 4),
+			entry("banque", "fra", "n00000001", 6),
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			name          string
+			lang          string
+			offset, count int64
+			want          []string
+		}{
+			// bank and bann both score 2 and are ordered by member
+			{name: "all languages", count: 10, want: []string{"ban", "bank", "bann", "banque"}},
+			{name: "one language", lang: "eng", count: 10, want: []string{"ban", "bank"}},
+			{name: "page", offset: 1, count: 2, want: []string{"bank", "bann"}},
+			{name: "past the end", offset: 4, count: 2, want: []string{}},
+			{name: "no count", count: 0, want: []string{}},
+			{name: "unknown language", lang: "ita", count: 10, want: []string{}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				entries, err := s.RangePrefix(ctx, 1, tt.lang, "ba", tt.offset, tt.count)
+				if err != nil {
+					t.Fatal(err)
+				}
+				words := make([]string, 0, len(entries))
+				for _, entry := range entries {
+					words = append(words, entry.Word)
+				}
+				if !reflect.DeepEqual(words, tt.want) {
+					t.Errorf("RangePrefix() = %v, want %v", words, tt.want)
+				}
+			})
+		}
+
+		entries, err := s.RangePrefix(ctx, 1, "eng", "ba", 1, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 || entries[0].Score != 2 {
+			t.Errorf("bank scores %v, want 2", entries)
+		}
+	})
+}
+
+func TestVersions(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s CognateStore) {
+		ctx := context.Background()
+
+		bank := model.Cognate{ConceptID: "n00000001", Lang1: "eng", Word1: "bank", Lang2: "deu", Word2: "Bank"}
+		if _, err := s.PutCognates(ctx, 1, []model.Cognate{bank}, false); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.CopyVersion(ctx, 1, 2); err != nil {
+			t.Fatal(err)
+		}
+
+		// Writes to the copy leave the original alone
+		banque := model.Cognate{ConceptID: "n00000001", Lang1: "eng", Word1: "bank", Lang2: "fra", Word2: "banque"}
+		if _, err := s.PutCognates(ctx, 2, []model.Cognate{banque}, false); err != nil {
+			t.Fatal(err)
+		}
+		for version, want := range map[int64]int{1: 1, 2: 2} {
+			cognates, err := s.GetConcept(ctx, version, "n00000001")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(cognates) != want {
+				t.Errorf("version %d has %d cognates, want %d", version, len(cognates), want)
+			}
+		}
+
+		if err := s.DeleteVersion(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+		cognates, err := s.GetConcept(ctx, 1, "n00000001")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cognates) != 0 {
+			t.Errorf("deleted version has %d cognates", len(cognates))
+		}
+		if _, err := s.GetVersion(ctx, 1); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetVersion() error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestScanConcepts(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s CognateStore) {
+		ctx := context.Background()
+
+		var cognates []model.Cognate
+		for _, conceptID := range []string{"n00000001", "n00000002", "v00000001"} {
+			cognates = append(cognates, model.Cognate{ConceptID: conceptID, Lang1: "eng", Word1: "a", Lang2: "deu", Word2: "b"})
+		}
+		if _, err := s.PutCognates(ctx, 1, cognates, false); err != nil {
+			t.Fatal(err)
+		}
+
+		var scanned []string
+		if err := s.ScanConcepts(ctx, 1, "n", func(conceptID string, cognates []model.Cognate) error {
+			// The store is not locked while fn runs
+			if _, err := s.GetConcept(ctx, 1, conceptID); err != nil {
+				return err
+			}
+			scanned = append(scanned, conceptID)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(scanned)
+		if !reflect.DeepEqual(scanned, []string{"n00000001", "n00000002"}) {
+			t.Errorf("scanned %v", scanned)
+		}
+
+		stop := errors.New("stop")
+		if err := s.ScanConcepts(ctx, 1, "", func(string, []model.Cognate) error { return stop }); !errors.Is(err, stop) {
+			t.Errorf("ScanConcepts() error = %v, want the error of fn", err)
+		}
+	})
+}
+
+func TestLeases(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s CognateStore) {
+		ctx := context.Background()
+
+		steps := []struct {
+			name  string
+			op    func() (bool, error)
+			want  bool
+			owner string
+		}{
+			{"acquire", func() (bool, error) { return s.AcquireLease(ctx, "import", "a", time.Minute) }, true, "a"},
+			{"acquire taken", func() (bool, error) { return s.AcquireLease(ctx, "import", "b", time.Minute) }, false, "a"},
+			{"renew by another owner", func() (bool, error) { return s.RenewLease(ctx, "import", "b", time.Minute) }, false, "a"},
+			{"renew", func() (bool, error) { return s.RenewLease(ctx, "import", "a", time.Minute) }, true, "a"},
+			{"release by another owner", func() (bool, error) { return true, s.ReleaseLease(ctx, "import", "b") }, true, "a"},
+			{"release", func() (bool, error) { return true, s.ReleaseLease(ctx, "import", "a") }, true, ""},
+			{"renew released", func() (bool, error) { return s.RenewLease(ctx, "import", "a", time.Minute) }, false, ""},
+			{"acquire expiring", func() (bool, error) { return s.AcquireLease(ctx, "import", "a", shortTTL) }, true, "a"},
+			{"wait for expiry", func() (bool, error) { time.Sleep(2 * shortTTL); return true, nil }, true, ""},
+			{"acquire after expiry", func() (bool, error) { return s.AcquireLease(ctx, "import", "b", time.Minute) }, true, "b"},
+		}
+
+		for _, step := range steps {
+			ok, err := step.op()
+			if err != nil {
+				t.Fatalf("%s: %v", step.name, err)
+			}
+			if ok != step.want {
+				t.Errorf("%s = %v, want %v", step.name, ok, step.want)
+			}
+			owner, err := s.LeaseOwner(ctx, "import")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if owner != step.owner {
+				t.Errorf("%s: owner = %q, want %q", step.name, owner, step.owner)
+			}
+		}
+	})
+}
+
+func TestExpiringEntries(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s CognateStore) {
+		ctx := context.Background()
+
+		if err := s.SaveImportJob(ctx, &model.ImportJob{ID: "old"}, shortTTL); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SaveImportJob(ctx, &model.ImportJob{ID: "new"}, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(2 * shortTTL)
+		if _, err := s.GetImportJob(ctx, "old"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expired job error = %v, want ErrNotFound", err)
+		}
+		if job, err := s.GetImportJob(ctx, "new"); err != nil || job.ID != "new" {
+			t.Errorf("GetImportJob() = %v, %v", job, err)
+		}
+
+		if err := s.SaveClearToken(ctx, "token", "all", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if scope, err := s.TakeClearToken(ctx, "token"); err != nil || scope != "all" {
+			t.Errorf("TakeClearToken() = %q, %v", scope, err)
+		}
+		if _, err := s.TakeClearToken(ctx, "token"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("token taken twice, error = %v", err)
+		}
+
+		if err := s.SaveClearToken(ctx, "stale", "all", shortTTL); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(2 * shortTTL)
+		if _, err := s.TakeClearToken(ctx, "stale"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expired token error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestMemoryPurgesExpiredJobs(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	if err := s.SaveImportJob(ctx, &model.ImportJob{ID: "old"}, -time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveImportJob(ctx, &model.ImportJob{ID: "new"}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.(*memoryStore).jobs["old"]; ok {
+		t.Error("expired job was not purged")
+	}
+}
+
+func TestCopyVersionInBatches(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s CognateStore) {
+		ctx := context.Background()
+
+		// More concepts and index entries than a bolt batch holds
+		const concepts = boltBatchSize + boltBatchSize/2
+		cognates := make([]model.Cognate, 0, concepts)
+		entries := make([]WordEntry, 0, concepts)
+		for i := 0; i < concepts; i++ {
+			conceptID := fmt.Sprintf("n%08d", i)
+			word := fmt.Sprintf("word%d", i)
+			cognates = append(cognates, model.Cognate{ConceptID: conceptID, Lang1: "eng", Word1: word, Lang2: "deu", Word2: "wort"})
+			entries = append(entries, WordEntry{Word: word, Lang: "eng", ConceptID: conceptID, Prefixes: []string{"wo"}})
+		}
+		if _, err := s.PutCognates(ctx, 1, cognates, false); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.IndexWords(ctx, 1, entries); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.CopyVersion(ctx, 1, 2); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.DeleteVersion(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+
+		scanned := 0
+		if err := s.ScanConcepts(ctx, 2, "", func(conceptID string, cognates []model.Cognate) error {
+			if len(cognates) != 1 {
+				return fmt.Errorf("%s has %d cognates", conceptID, len(cognates))
+			}
+			scanned++
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if scanned != concepts {
+			t.Errorf("copied %d concepts, want %d", scanned, concepts)
+		}
+
+		last, err := s.RangePrefix(ctx, 2, "eng", "wo", concepts-1, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(last) != 1 {
+			t.Errorf("copied prefix index ends with %d entries past %d, want 1", len(last), concepts-1)
+		}
+		senses, err := s.WordSenses(ctx, 2, fmt.Sprintf("word%d", concepts-1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(senses) != 1 {
+			t.Errorf("last word has %d senses, want 1", len(senses))
+		}
+		if langs, err := s.DatasetLanguages(ctx, 2); err != nil || !reflect.DeepEqual(langs, []string{"eng"}) {
+			t.Errorf("DatasetLanguages() = %v, %v", langs, err)
+		}
+	})
+}
@@ -0,0 +1,296 @@
+package text
+
+// Stem reduces an English word to its stem with the Porter algorithm, so
+// that "domesticated" and "domestic" both become "domest". Words that are
+// not lowercase ASCII letters are returned as they are.
+func Stem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	s := &stemmer{b: []byte(word), k: len(word) - 1}
+	s.step1ab()
+	if s.k > 0 {
+		s.step1c()
+		s.step2()
+		s.step3()
+		s.step4()
+		s.step5()
+	}
+	return string(s.b[:s.k+1])
+}
+
+// stemmer holds a word being stemmed: b[0..k] is the current stem and j
+// the end of the stem before the suffix last matched by ends
+type stemmer struct {
+	b    []byte
+	k, j int
+}
+
+// cons reports whether b[i] is a consonant
+func (s *stemmer) cons(i int) bool {
+	switch s.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !s.cons(i-1)
+	}
+	return true
+}
+
+// m measures the number of vowel-consonant sequences in b[0..j]
+func (s *stemmer) m() int {
+	n, i := 0, 0
+	for {
+		if i > s.j {
+			return n
+		}
+		if !s.cons(i) {
+			break
+		}
+		i++
+	}
+	i++
+	for {
+		for {
+			if i > s.j {
+				return n
+			}
+			if s.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+		n++
+		for {
+			if i > s.j {
+				return n
+			}
+			if !s.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+	}
+}
+
+// vowelInStem reports whether b[0..j] contains a vowel
+func (s *stemmer) vowelInStem() bool {
+	for i := 0; i <= s.j; i++ {
+		if !s.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doubleC reports whether b[i-1..i] is a double consonant
+func (s *stemmer) doubleC(i int) bool {
+	return i >= 1 && s.b[i] == s.b[i-1] && s.cons(i)
+}
+
+// cvc reports whether b[i-2..i] is consonant-vowel-consonant and the last
+// consonant is not w, x or y, as in hop(e) or cav(e)
+func (s *stemmer) cvc(i int) bool {
+	if i < 2 || !s.cons(i) || s.cons(i-1) || !s.cons(i-2) {
+		return false
+	}
+	switch s.b[i] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+// ends reports whether b[0..k] ends with suffix, setting j to the end of
+// the remaining stem
+func (s *stemmer) ends(suffix string) bool {
+	l := len(suffix)
+	if l > s.k+1 || string(s.b[s.k-l+1:s.k+1]) != suffix {
+		return false
+	}
+	s.j = s.k - l
+	return true
+}
+
+// setTo replaces b[j+1..k] with replacement
+func (s *stemmer) setTo(replacement string) {
+	s.b = append(s.b[:s.j+1], replacement...)
+	s.k = s.j + len(replacement)
+}
+
+// r replaces the suffix when the stem before it has a measure above zero
+func (s *stemmer) r(replacement string) {
+	if s.m() > 0 {
+		s.setTo(replacement)
+	}
+}
+
+// replaceFirst applies the first rule whose suffix matches
+func (s *stemmer) replaceFirst(rules [][2]string) {
+	for _, rule := range rules {
+		if s.ends(rule[0]) {
+			s.r(rule[1])
+			return
+		}
+	}
+}
+
+// step1ab removes plurals and -ed or -ing
+func (s *stemmer) step1ab() {
+	if s.b[s.k] == 's' {
+		switch {
+		case s.ends("sses"):
+			s.k -= 2
+		case s.ends("ies"):
+			s.setTo("i")
+		case s.b[s.k-1] != 's':
+			s.k--
+		}
+	}
+
+	if s.ends("eed") {
+		if s.m() > 0 {
+			s.k--
+		}
+		return
+	}
+	if (s.ends("ed") || s.ends("ing")) && s.vowelInStem() {
+		s.k = s.j
+		switch {
+		case s.ends("at"):
+			s.setTo("ate")
+		case s.ends("bl"):
+			s.setTo("ble")
+		case s.ends("iz"):
+			s.setTo("ize")
+		case s.doubleC(s.k):
+			switch s.b[s.k] {
+			case 'l', 's', 'z':
+			default:
+				s.k--
+			}
+		default:
+			s.j = s.k
+			if s.m() == 1 && s.cvc(s.k) {
+				s.setTo("e")
+			}
+		}
+	}
+}
+
+// step1c turns a final y into i when there is another vowel in the stem
+func (s *stemmer) step1c() {
+	if s.ends("y") && s.vowelInStem() {
+		s.b[s.k] = 'i'
+	}
+}
+
+// step2 maps double suffixes to single ones, -ization to -ize and so on
+func (s *stemmer) step2() {
+	switch s.b[s.k-1] {
+	case 'a':
+		s.replaceFirst([][2]string{{"ational", "ate"}, {"tional", "tion"}})
+	case 'c':
+		s.replaceFirst([][2]string{{"enci", "ence"}, {"anci", "ance"}})
+	case 'e':
+		s.replaceFirst([][2]string{{"izer", "ize"}})
+	case 'l':
+		s.replaceFirst([][2]string{{"bli", "ble"}, {"alli", "al"}, {"entli", "ent"}, {"eli", "e"}, {"ousli", "ous"}})
+	case 'o':
+		s.replaceFirst([][2]string{{"ization", "ize"}, {"ation", "ate"}, {"ator", "ate"}})
+	case 's':
+		s.replaceFirst([][2]string{{"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"}, {"ousness", "ous"}})
+	case 't':
+		s.replaceFirst([][2]string{{"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"}})
+	case 'g':
+		s.replaceFirst([][2]string{{"logi", "log"}})
+	}
+}
+
+// step3 handles -ic-, -full, -ness and the like
+func (s *stemmer) step3() {
+	switch s.b[s.k] {
+	case 'e':
+		s.replaceFirst([][2]string{{"icate", "ic"}, {"ative", ""}, {"alize", "al"}})
+	case 'i':
+		s.replaceFirst([][2]string{{"iciti", "ic"}})
+	case 'l':
+		s.replaceFirst([][2]string{{"ical", "ic"}, {"ful", ""}})
+	case 's':
+		s.replaceFirst([][2]string{{"ness", ""}})
+	}
+}
+
+// step4 removes -ant, -ence and similar suffixes from longer stems
+func (s *stemmer) step4() {
+	var suffixes []string
+	switch s.b[s.k-1] {
+	case 'a':
+		suffixes = []string{"al"}
+	case 'c':
+		suffixes = []string{"ance", "ence"}
+	case 'e':
+		suffixes = []string{"er"}
+	case 'i':
+		suffixes = []string{"ic"}
+	case 'l':
+		suffixes = []string{"able", "ible"}
+	case 'n':
+		suffixes = []string{"ant", "ement", "ment", "ent"}
+	case 'o':
+		if s.ends("ion") && s.j >= 0 && (s.b[s.j] == 's' || s.b[s.j] == 't') {
+			break
+		}
+		suffixes = []string{"ou"}
+	case 's':
+		suffixes = []string{"ism"}
+	case 't':
+		suffixes = []string{"ate", "iti"}
+	case 'u':
+		suffixes = []string{"ous"}
+	case 'v':
+		suffixes = []string{"ive"}
+	case 'z':
+		suffixes = []string{"ize"}
+	default:
+		return
+	}
+
+	if suffixes != nil {
+		matched := false
+		for _, suffix := range suffixes {
+			if s.ends(suffix) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return
+		}
+	}
+	if s.m() > 1 {
+		s.k = s.j
+	}
+}
+
+// step5 removes a final -e and reduces -ll on longer stems
+func (s *stemmer) step5() {
+	s.j = s.k
+	if s.b[s.k] == 'e' {
+		a := s.m()
+		if a > 1 || (a == 1 && !s.cvc(s.k-1)) {
+			s.k--
+		}
+	}
+	if s.b[s.k] == 'l' && s.doubleC(s.k) && s.m() > 1 {
+		s.k--
+	}
+}
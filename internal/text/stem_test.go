@@ -0,0 +1,89 @@
+package text
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	// Pairs from the reference vocabulary of the Porter algorithm
+	tests := []struct {
+		word string
+		want string
+	}{
+		// Step 1a: plurals
+		{"caresses", "caress"},
+		{"ponies", "poni"},
+		{"ties", "ti"},
+		{"caress", "caress"},
+		{"cats", "cat"},
+		// Step 1b: -ed and -ing
+		{"feed", "feed"},
+		{"agreed", "agre"},
+		{"plastered", "plaster"},
+		{"motoring", "motor"},
+		{"sing", "sing"},
+		{"conflated", "conflat"},
+		{"troubled", "troubl"},
+		{"sized", "size"},
+		{"hopping", "hop"},
+		{"falling", "fall"},
+		{"hissing", "hiss"},
+		{"failing", "fail"},
+		{"filing", "file"},
+		// Step 1c: y to i
+		{"happy", "happi"},
+		{"sky", "sky"},
+		// Steps 2 to 4: suffixes
+		{"relational", "relat"},
+		{"conditional", "condit"},
+		{"digitizer", "digit"},
+		{"hopefulness", "hope"},
+		{"sensitiviti", "sensit"},
+		{"triplicate", "triplic"},
+		{"electrical", "electr"},
+		{"allowance", "allow"},
+		{"adjustable", "adjust"},
+		{"replacement", "replac"},
+		{"effective", "effect"},
+		// Step 5: final e and double l
+		{"probate", "probat"},
+		{"rate", "rate"},
+		{"cease", "ceas"},
+		{"controll", "control"},
+		{"roll", "roll"},
+		// Words of the concept glosses
+		{"generalizations", "gener"},
+		{"domesticated", "domest"},
+		{"carnivore", "carnivor"},
+		{"fishes", "fish"},
+		// Too short to stem
+		{"is", "is"},
+		{"a", "a"},
+	}
+
+	for _, tt := range tests {
+		if got := Stem(tt.word); got != tt.want {
+			t.Errorf("Stem(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"The domestic cat", []string{"domest", "cat"}},
+		{"house_cat, Felis-catus!", []string{"hous", "cat", "feli", "catu"}},
+		{"cats and CATS", []string{"cat", "cat"}},
+		{"of the", []string{}},
+		{"", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := Terms(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Terms(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Package text turns English text into search terms.
+package text
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords are frequent English words that carry no meaning for a search
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "has": true, "have": true,
+	"in": true, "into": true, "is": true, "it": true, "its": true, "of": true,
+	"on": true, "or": true, "that": true, "the": true, "this": true, "to": true,
+	"was": true, "were": true, "which": true, "with": true,
+}
+
+// Terms splits text into lowercase words, drops stop words and stems the
+// rest. Terms repeat as often as their words do.
+func Terms(s string) []string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := words[:0]
+	for _, word := range words {
+		if stopWords[word] {
+			continue
+		}
+		terms = append(terms, Stem(word))
+	}
+	return terms
+}
+
+// Frequencies counts the terms of text
+func Frequencies(s string) map[string]int {
+	frequencies := make(map[string]int)
+	for _, term := range Terms(s) {
+		frequencies[term]++
+	}
+	return frequencies
+}